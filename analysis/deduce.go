@@ -0,0 +1,132 @@
+package analysis
+
+import (
+	"slices"
+	"strings"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// observableEvents are the events that tell a player something about the terrain
+var observableEvents = map[lab.EventType]struct{}{
+	lab.LearnCellEventType:  {},
+	lab.RiverDragEventType:  {},
+	lab.TeleportEventType:   {},
+	lab.LockedDoorEventType: {},
+	lab.UnlockDoorEventType: {},
+}
+
+type turn struct {
+	dir          lab.MoveDirection
+	observations []lab.Event
+	keys         []string
+}
+
+// splitTurns groups the player's events into moves with what the player observed after each of them.
+// history is the stream of events emitted by the World, as received from World.SetChannel.
+func splitTurns(player string, history []lab.Event) []turn {
+	var res []turn
+	keys := []string{}
+
+	for _, e := range history {
+		if e.Subject != player {
+			continue
+		}
+
+		switch e.Type {
+		case lab.MoveEventType:
+			dir, err := lab.MoveDirectionFromWord(e.Value)
+			if err != nil {
+				continue
+			}
+			res = append(res, turn{dir: dir, keys: slices.Clone(keys)})
+
+		case lab.PickObjectEventType:
+			if tag, ok := strings.CutSuffix(e.Value, " key"); ok {
+				keys = append(keys, tag)
+			}
+
+		case lab.DropObjectEventType, lab.LooseObjectEventType:
+			if tag, ok := strings.CutSuffix(e.Value, " key"); ok {
+				if i := slices.Index(keys, tag); i >= 0 {
+					keys = slices.Delete(keys, i, i+1)
+				}
+			}
+
+		default:
+			if _, ok := observableEvents[e.Type]; ok && len(res) > 0 {
+				res[len(res)-1].observations = append(res[len(res)-1].observations, e)
+			}
+		}
+	}
+
+	return res
+}
+
+func observable(evs []lab.Event) []lab.Event {
+	var res []lab.Event
+	for _, e := range evs {
+		if _, ok := observableEvents[e.Type]; ok {
+			res = append(res, lab.Event{Type: e.Type, Value: e.Value})
+		}
+	}
+
+	return res
+}
+
+// simulate replays the move from pos and reports where the player ends up and whether
+// the outcome matches what the player observed
+func simulate(w *lab.World, pos lab.Position, t turn) (lab.Position, bool) {
+	p := lab.NewPlayer("", pos)
+	p.Inventory.Capacity = 0
+	for _, tag := range t.keys {
+		p.Inventory.Put(lab.NewKeyItem(tag))
+	}
+
+	evs := observable((&lab.MoveCommand{Direction: t.dir}).Do(w, p))
+	want := observable(t.observations)
+
+	return p.Pos, slices.Equal(evs, want)
+}
+
+// Candidates returns every cell the player could be on, given the events the player has heard so far.
+// If starts is empty any cell except walls may be the starting one.
+func Candidates(w *lab.World, player string, history []lab.Event, starts []lab.Position) []lab.Position {
+	// simulation mutates items on cells, so it runs on a private copy of the world
+	sandbox := &lab.World{Cells: w.Cells.Clone()}
+
+	if len(starts) == 0 {
+		for p, c := range sandbox.Cells.All() {
+			if c.Class != lab.CellWall {
+				starts = append(starts, p)
+			}
+		}
+	}
+
+	current := slices.Clone(starts)
+	for _, t := range splitTurns(player, history) {
+		next := current[:0]
+		for _, pos := range current {
+			if nextPos, ok := simulate(sandbox, pos, t); ok {
+				next = append(next, nextPos)
+			}
+		}
+		current = next
+	}
+
+	slices.SortFunc(current, comparePositions)
+	return slices.Compact(current)
+}
+
+// Verify checks that the position claimed by the player agrees with the events the player has heard
+func Verify(w *lab.World, player string, history []lab.Event, starts []lab.Position, claim lab.Position) bool {
+	return slices.Contains(Candidates(w, player, history, starts), claim)
+}
+
+func comparePositions(a, b lab.Position) int {
+	if a.Y != b.Y {
+		return a.Y - b.Y
+	}
+
+	return a.X - b.X
+}
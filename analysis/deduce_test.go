@@ -0,0 +1,67 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+func play(w *lab.World, start lab.Position, moves ...string) []lab.Event {
+	ch := make(chan lab.Event, 100)
+	w.SetChannel(ch)
+	defer w.SetChannel(nil)
+
+	s := &lab.Session{World: w}
+	s.AddPlayer("alex", start)
+	for _, m := range moves {
+		s.Do(m)
+	}
+	close(ch)
+
+	var res []lab.Event
+	for e := range ch {
+		res = append(res, e)
+	}
+
+	return res
+}
+
+func TestCandidates(t *testing.T) {
+	w := lab.NewWorldFromString(`
+wwwwww
+w    w
+w w  w
+w    w
+wwwwww
+`)
+
+	history := play(w, lab.NewPosition(1, 1), "north")
+	assert.Equal(t, []lab.Position{
+		lab.NewPosition(1, 1), lab.NewPosition(2, 1), lab.NewPosition(3, 1), lab.NewPosition(4, 1),
+		lab.NewPosition(2, 3),
+	}, Candidates(w, "alex", history, nil))
+
+	history = play(w, lab.NewPosition(1, 1), "north", "west")
+	assert.Equal(t, []lab.Position{lab.NewPosition(1, 1)}, Candidates(w, "alex", history, nil))
+
+	history = play(w, lab.NewPosition(1, 1), "north", "west", "south", "east")
+	assert.Equal(t, []lab.Position{lab.NewPosition(1, 2)}, Candidates(w, "alex", history, nil))
+	assert.True(t, Verify(w, "alex", history, nil, lab.NewPosition(1, 2)))
+	assert.False(t, Verify(w, "alex", history, nil, lab.NewPosition(2, 2)))
+}
+
+func TestCandidates_River(t *testing.T) {
+	w := lab.NewWorld([][]string{
+		{"w", "w", "w", "w", "w"},
+		{"w", "", "", "", "w"},
+		{"w", "→", "→", "RM", "w"},
+		{"w", "w", "w", "w", "w"},
+	})
+
+	history := play(w, lab.NewPosition(1, 1), "south")
+	assert.Equal(t, []lab.Position{lab.NewPosition(3, 2)}, Candidates(w, "alex", history, []lab.Position{
+		lab.NewPosition(1, 1), lab.NewPosition(2, 1), lab.NewPosition(3, 1),
+	}))
+}
@@ -0,0 +1,66 @@
+package labyrinth
+
+import (
+	"fmt"
+	"strings"
+)
+
+const DoorCellKeyAttr = "door_key"
+
+// DoorCell is a cell that can be passed only by a player carrying the key with the same tag
+type DoorCell struct {
+	Key string
+}
+
+func (c DoorCell) Type() CellType {
+	return CellType{
+		Class:      CellDoor,
+		Name:       "door",
+		Attributes: map[string]string{DoorCellKeyAttr: c.Key},
+	}
+}
+
+func NewKeyItem(tag string) *Item {
+	return &Item{ID: Key, Name: tag + " key", Tag: tag}
+}
+
+type DoorStringCellFactory struct {
+}
+
+func (dscf DoorStringCellFactory) Make(key string, pos Position) (Cell, error) {
+	_, keyName, ok := strings.Cut(key, ":")
+	keyName = strings.TrimSpace(keyName)
+	if !ok || keyName == "" {
+		return nil, fmt.Errorf("invalid door cell: `%v`", key)
+	}
+
+	return &CellType{Class: CellDoor, Custom: &DoorCell{Key: keyName}}, nil
+}
+
+func (dscf DoorStringCellFactory) Finish(cm CellMap) error {
+	return nil
+}
+
+type DoorMoveCommand struct{}
+
+func (c DoorMoveCommand) Do(w *World, p *Player, direction MoveDirection) []Event {
+	nextCell := w.Cells.Get(p.Pos.Next(direction))
+
+	door, ok := nextCell.Custom.(*DoorCell)
+	if !ok {
+		e := NewEventf2(ErrorEventType, p.Name, "ERROR: can't cast to door "+nextCell.Class)
+		w.Emmit(e)
+		return []Event{e}
+	}
+
+	if !p.Inventory.HasKey(door.Key) {
+		e := NewEventf2(LockedDoorEventType, p.Name, door.Key)
+		w.Emmit(e)
+		return []Event{e}
+	}
+
+	e := NewEventf2(UnlockDoorEventType, p.Name, door.Key)
+	w.Emmit(e)
+
+	return append([]Event{e}, SimpleMoveCommand{}.Do(w, p, direction)...)
+}
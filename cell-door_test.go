@@ -0,0 +1,66 @@
+package labyrinth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoorMoveCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     []*Item
+		wantPos   Position
+		wantEvent EventType
+	}{
+		{
+			name:      "locked without key",
+			wantPos:   NewPosition(1, 1),
+			wantEvent: LockedDoorEventType,
+		},
+		{
+			name:      "locked with another key",
+			items:     []*Item{NewKeyItem("blue")},
+			wantPos:   NewPosition(1, 1),
+			wantEvent: LockedDoorEventType,
+		},
+		{
+			name:      "opens with matching key",
+			items:     []*Item{NewKeyItem("red")},
+			wantPos:   NewPosition(2, 1),
+			wantEvent: UnlockDoorEventType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWorld([][]string{
+				{"w", "w", "w", "w"},
+				{"w", "", "D:red", "w"},
+				{"w", "w", "w", "w"},
+			})
+			p := NewPlayer("alex", NewPosition(1, 1))
+			for _, item := range tt.items {
+				p.Inventory.Put(item)
+			}
+
+			evs := (&MoveCommand{Direction: East}).Do(w, p)
+
+			assert.Equal(t, tt.wantPos, p.Pos)
+			if assert.NotEmpty(t, evs) {
+				assert.Equal(t, tt.wantEvent, evs[0].Type)
+			}
+		})
+	}
+}
+
+func TestInventory_Capacity(t *testing.T) {
+	inv := NewInventory(1)
+
+	assert.True(t, inv.Put(NewKeyItem("red")))
+	assert.False(t, inv.Put(NewKeyItem("blue")))
+	assert.True(t, inv.HasKey("red"))
+	assert.False(t, inv.HasKey("blue"))
+
+	assert.NotNil(t, inv.Take("red key"))
+	assert.True(t, inv.IsEmpty())
+}
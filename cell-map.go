@@ -1,11 +1,11 @@
 package labyrinth
 
 import (
-	"fmt"
 	"io"
 	"iter"
 	"log"
 	"os"
+	"slices"
 )
 
 type CellMap struct {
@@ -37,6 +37,9 @@ func (cm *CellMap) Insert(c Cell, p Position) {
 }
 
 func (cm *CellMap) Get(p Position) Cell {
+	if p.X < 0 || p.Y < 0 {
+		return &CellType{Class: "wall"}
+	}
 	if p.Y >= len(cm.v) {
 		return &CellType{Class: "wall"}
 	}
@@ -47,6 +50,24 @@ func (cm *CellMap) Get(p Position) Cell {
 	return cm.v[p.Y][p.X]
 }
 
+// Clone copies cells and their items. Custom cell data is shared
+func (cm *CellMap) Clone() CellMap {
+	res := CellMap{v: make([][]Cell, len(cm.v))}
+	for y, row := range cm.v {
+		res.v[y] = make([]Cell, len(row))
+		for x, c := range row {
+			if c == nil {
+				continue
+			}
+			cc := *c
+			cc.Items = slices.Clone(c.Items)
+			res.v[y][x] = &cc
+		}
+	}
+
+	return res
+}
+
 func (cm *CellMap) All() iter.Seq2[Position, Cell] {
 	return func(yield func(Position, Cell) bool) {
 		for y, row := range cm.v {
@@ -86,7 +107,6 @@ func FPrintCellMap(w io.Writer, cellMap CellMap) {
 		if p.Y > lastY { // nextrow
 			lastY = p.Y
 			if lastY != 0 { // exception for first row
-				fmt.Println()
 				_, err := w.Write([]byte{'\n'})
 				if err != nil {
 					log.Print(err.Error())
@@ -139,6 +159,26 @@ func (pm *PlayerMap) Rect() (int, int) {
 	return pm.RightCorner.X - pm.LeftCorner.X + 1, pm.RightCorner.Y - pm.LeftCorner.Y + 1
 }
 
+func (pm *PlayerMap) Clone() PlayerMap {
+	res := PlayerMap{
+		LeftCorner:  pm.LeftCorner,
+		RightCorner: pm.RightCorner,
+		KnonwnCells: make(map[Position]struct{}, len(pm.KnonwnCells)),
+	}
+	for p := range pm.KnonwnCells {
+		res.KnonwnCells[p] = struct{}{}
+	}
+
+	return res
+}
+
+// Merge learns every cell known by the other map
+func (pm *PlayerMap) Merge(other PlayerMap) {
+	for p := range other.KnonwnCells {
+		pm.Learn(p)
+	}
+}
+
 func (cf *PlayerMap) Learn(pos Position) {
 	if cf.KnonwnCells == nil {
 		cf.KnonwnCells = map[Position]struct{}{}
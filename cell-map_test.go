@@ -8,7 +8,7 @@ import (
 )
 
 func TestFPrintCellMap(t *testing.T) {
-	w := WallCell{}
+	w := &CellType{Class: CellWall}
 
 	tests := []struct {
 		name    string
@@ -56,7 +56,7 @@ func TestFPrintCellMap(t *testing.T) {
 }
 
 func TestCellMap_Insert(t *testing.T) {
-	w := WallCell{}
+	w := &CellType{Class: CellWall}
 
 	tests := []struct {
 		name string
@@ -77,7 +77,7 @@ func TestCellMap_Insert(t *testing.T) {
 				nil,
 				nil,
 				nil,
-				{WallCell{pos: NewPosition(0, 3)}, WallCell{pos: NewPosition(1, 3)}, WallCell{pos: NewPosition(2, 3)}, WallCell{pos: NewPosition(0, 0)}},
+				{&CellType{Class: CellWall}, &CellType{Class: CellWall}, &CellType{Class: CellWall}, w},
 			},
 		},
 	}
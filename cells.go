@@ -7,6 +7,7 @@ const (
 	CellRiverMouth = "river mouth"
 	CellExit       = "exit"
 	CellWormHole   = "wormhole"
+	CellDoor       = "door"
 )
 
 type SimpleStringCellFactory struct {
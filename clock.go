@@ -0,0 +1,23 @@
+package labyrinth
+
+import "time"
+
+type Timer interface {
+	Stop() bool
+}
+
+// Clock is the source of time for timers. Replace it in tests to avoid sleeping
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
@@ -2,19 +2,25 @@ package main
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
 	lab "github.com/kepkin/labyrinth"
+	"github.com/kepkin/labyrinth/strategy"
+	"github.com/kepkin/labyrinth/text"
 	labtv "github.com/kepkin/labyrinth/tview"
 )
 
-func Run(gameSession *lab.Session) {
+// Run plays the game in the terminal. With fog the table and the log show only what the current player knows,
+// the screen is hidden until the next player takes the terminal and the whole map with paths of all players
+// is revealed at the end.
+func Run(gameSession *lab.Session, bots []*strategy.Bot, fog bool) {
 	w := gameSession.World
 	players := gameSession.Players
 
-	worldChannel := make(chan lab.Event, 10)
+	worldChannel := make(chan lab.Event, 100)
 	w.SetChannel(worldChannel)
 	// background := tview.NewTextView().
 	// 	SetTextColor(tcell.ColorBlue).
@@ -23,7 +29,7 @@ func Run(gameSession *lab.Session) {
 	tb := tview.NewTable()
 	tb.SetBackgroundColor(tcell.ColorDefault)
 
-	mtc := labtv.NewWorldTable(w, players)
+	mtc := labtv.NewWorldTable(w, gameSession)
 	tb.SetContent(&mtc)
 
 	app := tview.NewApplication()
@@ -40,12 +46,68 @@ func Run(gameSession *lab.Session) {
 	posView := tview.NewTextView()
 	posView.SetDynamicColors(true).SetBackgroundColor(tcell.ColorDefault)
 
+	pages := tview.NewPages()
+	var renderLog func()
+	hideScreen := tview.NewModal().AddButtons([]string{"I'm ready"})
+
+	showPlayers := func() {
+		posView.Clear()
+		if !fog {
+			for _, p := range players {
+				fmt.Fprintf(posView, "player %v - %s\n", p.Name, p.Pos)
+			}
+			return
+		}
+
+		p := mtc.Viewer()
+		if p == nil {
+			return
+		}
+		fmt.Fprintf(posView, "%v\nlives: %v\n", p.Name, p.Lives)
+		for _, v := range p.Inventory.Items {
+			fmt.Fprintf(posView, "carrying: %v\n", v.Name)
+		}
+		if gameSession.HasUncertainty(p) {
+			fmt.Fprintln(posView, "[yellow]you don't know where you are,\nyour map starts again on the next move[-]")
+		}
+		// fragments are drawn apart from the table, nobody knows where they lie on the current map
+		for i := range p.Fragments {
+			fmt.Fprintf(posView, "\nfragment %v\n%v", i+1, tview.Escape(text.String(&w.Cells, text.Options{Style: text.Unicode, View: &p.Fragments[i]})))
+		}
+	}
+
+	// passTurn shows the view of the current player. The screen is hidden when another human takes the terminal
+	passTurn := func() {
+		if !fog || gameSession.IsOver() {
+			return
+		}
+
+		p := gameSession.GetCurrentPlayer()
+		if p != mtc.Viewer() {
+			hideScreen.SetText(fmt.Sprintf("Pass the terminal to %v", p.Name))
+			pages.SwitchToPage("hide")
+			app.SetFocus(hideScreen)
+		}
+		mtc.SetViewer(p)
+		showPlayers()
+		renderLog()
+	}
+
+	hideScreen.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		pages.SwitchToPage("game")
+		app.SetFocus(dropdown)
+	})
+
 	var setOptions func(actions []string)
 
 	dropdownSelFunc := func(text string, index int) {
-		gameSession.Do(text)
+		evs := gameSession.Do(text)
+		strategy.Broadcast(bots, evs...)
+		strategy.PlayBots(gameSession, bots)
+
 		actions := gameSession.GetCurrentPlayerPossibleActions()
 		setOptions(actions)
+		passTurn()
 	}
 
 	setOptions = func(actions []string) {
@@ -64,80 +126,118 @@ func Run(gameSession *lab.Session) {
 	hFlex.AddItem(posView, 0, 1, false)
 	hFlex.AddItem(logView, 0, 1, false)
 
-	worldEventHandler := func(event lab.Event) {
-		app.QueueUpdateDraw(func() {
-			fmt.Fprint(logView, LabEventToString(event)+"\n")
-			logView.ScrollToEnd()
+	eventStringer := lab.DefaultEventStringer{}
+	var history []lab.Event
 
-			posView.Clear()
-			for _, p := range players {
-				fmt.Fprintf(posView, "player %v - %s\n", p.Name, p.Pos)
-			}
+	// isViewers tells if the event happened to the viewer or their teammate
+	isViewers := func(viewer *lab.Player, e lab.Event) bool {
+		p := gameSession.FindPlayer(e.Subject)
+		return p == viewer || (p != nil && p.Team != "" && p.Team == viewer.Team)
+	}
 
-			if event.Type == lab.WinEventType {
-				app.Stop()
+	renderLog = func() {
+		logView.Clear()
+		viewer := mtc.Viewer()
+		for _, e := range history {
+			text := tview.Escape(eventStringer.ToString(e))
+			switch {
+			case viewer == nil:
+				fmt.Fprintln(logView, text)
+			case isViewers(viewer, e):
+				fmt.Fprintf(logView, "[green]%v[-]\n", text)
+			case lab.IsPublic(e):
+				fmt.Fprintln(logView, text)
 			}
-		})
+		}
+		logView.ScrollToEnd()
 	}
 
-	go func() {
-		worldEventHandler(lab.NewEventf2(lab.GameStartEventType, "", ""))
-		for event := range worldChannel {
-			worldEventHandler(event)
+	// reveal shows the whole map with paths of all players at the end of a hot-seat game
+	reveal := func(gameOver lab.Event) {
+		mtc.SetViewer(nil)
+		mtc.ShowPaths(gameSession.Paths())
+		renderLog()
 
+		posView.Clear()
+		fmt.Fprintf(posView, "Game over\n%v\n\n", tview.Escape(gameOver.Value))
+		for idx, p := range players {
+			fmt.Fprintf(posView, "[#%06x]%v %v[-]\n", labtv.PlayerColor(idx).Hex(), idx, tview.Escape(p.Name))
 		}
-	}()
 
-	if err := app.SetRoot(hFlex, true).Run(); err != nil {
-		panic(err)
+		dropdown.SetOptions([]string{"quit"}, func(string, int) { app.Stop() })
+		pages.SwitchToPage("game")
+		app.SetFocus(dropdown)
 	}
-}
 
-func LabEventToString(ev lab.Event) string {
-	switch ev.Type {
-	case lab.MoveEventType:
-		return fmt.Sprintf("Player %v moved %v", ev.Subject, ev.Value)
-
-	case lab.WinEventType:
-		return fmt.Sprintf("Player %v WINS", ev.Subject)
-
-	case lab.ExitEventType:
-		return fmt.Sprintf("Player %v found exit", ev.Subject)
-
-	case lab.LearnCellEventType:
-		return fmt.Sprintf("Player %v is on %v", ev.Subject, ev.Value)
-
-	case lab.RiverDragEventType:
-		return fmt.Sprintf("Player %v dragged downstream", ev.Subject)
+	worldEventHandler := func(events []lab.Event) {
+		app.QueueUpdateDraw(func() {
+			history = append(history, events...)
+			renderLog()
+			showPlayers()
+
+			for _, event := range events {
+				if event.Type != lab.GameOverEventType {
+					continue
+				}
+
+				if fog {
+					reveal(event)
+				} else {
+					app.Stop()
+				}
+				return
+			}
+		})
+	}
 
-	case lab.PickObjectEventType:
-		return fmt.Sprintf("Player %v picked up %v", ev.Subject, ev.Value)
+	go collectEvents(worldChannel, worldEventHandler, lab.NewEventf2(lab.GameStartEventType, gameSession.GetCurrentPlayer().Name, ""))
 
-	case lab.DropObjectEventType:
-		return fmt.Sprintf("Player %v dropped %v", ev.Subject, ev.Value)
+	pages.AddPage("game", hFlex, true, true)
+	pages.AddPage("hide", hideScreen, true, false)
 
-	case lab.LooseObjectEventType:
-		return fmt.Sprintf("Player %v loosed %v", ev.Subject, ev.Value)
+	// QueueUpdate waits for the update to be done, so it's queued from a goroutine before the app runs
+	go app.QueueUpdateDraw(func() {
+		strategy.PlayBots(gameSession, bots)
+		setOptions(gameSession.GetCurrentPlayerPossibleActions())
+		passTurn()
+	})
 
-	case lab.ErrorEventType:
-		return fmt.Sprint("Unexpected error")
+	if err := app.SetRoot(pages, true).Run(); err != nil {
+		panic(err)
+	}
+}
 
-	case lab.FoundObjectEventType:
-		return fmt.Sprintf("Player %v found %v", ev.Subject, ev.Value)
+// collectEvents reads the channel without waiting for the handler and passes it everything read so far,
+// starting with the first events. Bots play inside UI callbacks, so the world must not wait for the UI to emit
+// their events.
+func collectEvents(ch <-chan lab.Event, handle func(events []lab.Event), first ...lab.Event) {
+	var mu sync.Mutex
+	pending := first
+	ready := make(chan struct{}, 1)
+	ready <- struct{}{}
 
-	case lab.RevealObjectEventType:
-		if ev.Value == "genuine" {
-			return fmt.Sprintf("Player's treasure is genuine")
+	go func() {
+		for range ready {
+			mu.Lock()
+			events := pending
+			pending = nil
+			mu.Unlock()
+
+			if len(events) > 0 {
+				handle(events)
+			}
 		}
-		return fmt.Sprintf("Player's treasure is fake")
-
-	case lab.TeleportEventType:
-		return fmt.Sprintf("Player %v was teleported", ev.Subject)
+	}()
 
-	case lab.GameStartEventType:
-		return fmt.Sprintf("Game started. Player %v is the first to move", ev.Subject)
+	for event := range ch {
+		mu.Lock()
+		pending = append(pending, event)
+		mu.Unlock()
 
+		select {
+		case ready <- struct{}{}:
+		default:
+		}
 	}
-
-	return "Unsupported event"
+	close(ready)
 }
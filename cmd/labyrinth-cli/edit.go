@@ -0,0 +1,367 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	lab "github.com/kepkin/labyrinth"
+	md "github.com/kepkin/labyrinth/markdown"
+	"github.com/kepkin/labyrinth/solver"
+	labtv "github.com/kepkin/labyrinth/tview"
+)
+
+const newMapSize = 8
+
+const editorHelp = `arrows     move the cursor
+space      earth
+#          wall
+r  m       river, river mouth (RM)
+< > ^ v    river flowing west, east, north, south
+o          wormhole
+d          door
+e          exit on the outer wall
+t  f       treasure, fake treasure
+k          key
+p          player start
+M          minotaur
+x          remove everything but the cell
+[ ] { }    fewer/more columns and rows
+ctrl+s     save
+q  esc     quit`
+
+// reservedNames are property names which can't be player names
+var reservedNames = []string{"exit", "treasure", "fake_treasure", "key", "minotaur"}
+
+type editor struct {
+	path  string
+	doc   *md.Document
+	saved bool
+
+	message   string
+	quitArmed bool
+	// lastWormhole is the system of the last placed wormhole, new holes continue it
+	lastWormhole string
+
+	app    *tview.Application
+	table  *tview.Table
+	status *tview.TextView
+	prompt *tview.InputField
+}
+
+func openDocument(path string) (*md.Document, bool, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return md.NewDocument(newMapSize, newMapSize), false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	doc, err := md.ReadDocument(string(b))
+	return doc, true, err
+}
+
+func edit(path string) error {
+	doc, saved, err := openDocument(path)
+	if err != nil {
+		return err
+	}
+
+	e := &editor{
+		path:         path,
+		doc:          doc,
+		saved:        saved,
+		lastWormhole: "A",
+		app:          tview.NewApplication(),
+		table:        tview.NewTable(),
+		status:       tview.NewTextView(),
+		prompt:       tview.NewInputField(),
+	}
+
+	e.table.SetBackgroundColor(tcell.ColorDefault)
+	e.table.SetSelectable(true, true)
+	e.table.Select(1, 1)
+	e.table.SetSelectionChangedFunc(func(row, column int) { e.showStatus() })
+	e.table.SetInputCapture(e.handleKey)
+
+	e.status.SetDynamicColors(true).SetBackgroundColor(tcell.ColorDefault)
+	e.prompt.SetBackgroundColor(tcell.ColorDefault)
+
+	help := tview.NewTextView().SetText(editorHelp)
+	help.SetBackgroundColor(tcell.ColorDefault)
+
+	right := tview.NewFlex().SetDirection(tview.FlexRow)
+	right.AddItem(e.status, 0, 1, false)
+	right.AddItem(help, strings.Count(editorHelp, "\n")+1, 0, false)
+
+	hFlex := tview.NewFlex()
+	hFlex.AddItem(e.table, 0, 1, true)
+	hFlex.AddItem(right, 0, 1, false)
+
+	root := tview.NewFlex().SetDirection(tview.FlexRow)
+	root.AddItem(hFlex, 0, 1, true)
+	root.AddItem(e.prompt, 1, 0, false)
+
+	e.refresh()
+
+	return e.app.SetRoot(root, true).Run()
+}
+
+func (e *editor) cursor() lab.Position {
+	row, column := e.table.GetSelection()
+	return lab.NewPosition(column, row)
+}
+
+// changed is called after every change of the document
+func (e *editor) changed() {
+	e.saved = false
+	e.refresh()
+}
+
+func (e *editor) refresh() {
+	w, pls, _ := e.doc.Preview()
+
+	mtc := labtv.NewWorldTable(w, &lab.Session{World: w, Players: pls})
+	e.table.SetContent(&mtc)
+
+	e.showStatus()
+}
+
+func (e *editor) showStatus() {
+	e.status.Clear()
+
+	name := e.path
+	if !e.saved {
+		name += " [yellow](modified)[-]"
+	}
+	fmt.Fprintf(e.status, "%v  %vx%v\n", name, e.doc.Width(), e.doc.Height())
+
+	pos := e.cursor()
+	fmt.Fprintf(e.status, "cursor %v  cell `%v`", pos, tview.Escape(e.doc.Code(pos)))
+	for _, v := range e.doc.PropertiesAt(pos) {
+		fmt.Fprintf(e.status, "  %v", tview.Escape(strings.Join(append([]string{v.Name}, v.Args...), ":")))
+	}
+	fmt.Fprintln(e.status)
+
+	if e.message != "" {
+		fmt.Fprintf(e.status, "[red]%v[-]\n", tview.Escape(e.message))
+	}
+	fmt.Fprintln(e.status)
+
+	problems := e.doc.Check()
+	if len(problems) > 0 {
+		fmt.Fprintln(e.status, "problems:")
+		for _, v := range problems {
+			fmt.Fprintf(e.status, "  %v\n", tview.Escape(v.String()))
+		}
+		return
+	}
+
+	w, pls, err := e.doc.Build()
+	if err != nil {
+		fmt.Fprintf(e.status, "[red]%v[-]\n", tview.Escape(err.Error()))
+		return
+	}
+
+	fmt.Fprintln(e.status, "no problems, shortest wins:")
+	s := solver.New(w)
+	for _, p := range pls {
+		sol, err := s.Solve(p.Pos)
+		if err != nil {
+			fmt.Fprintf(e.status, "  %v: [red]unsolvable[-]\n", tview.Escape(p.Name))
+			continue
+		}
+		fmt.Fprintf(e.status, "  %v: %v moves\n", tview.Escape(p.Name), sol.Moves())
+	}
+}
+
+// ask shows the prompt and calls done with the answer. Escape cancels it
+func (e *editor) ask(label string, value string, done func(text string)) {
+	e.prompt.SetLabel(label + ": ").SetText(value)
+	e.prompt.SetDoneFunc(func(key tcell.Key) {
+		text := strings.TrimSpace(e.prompt.GetText())
+		e.prompt.SetLabel("").SetText("")
+		e.app.SetFocus(e.table)
+
+		if key == tcell.KeyEnter && text != "" {
+			done(text)
+		}
+	})
+	e.app.SetFocus(e.prompt)
+}
+
+func (e *editor) setCode(code string) {
+	if err := e.doc.SetCode(e.cursor(), code); err != nil {
+		e.message = err.Error()
+		e.showStatus()
+		return
+	}
+	e.changed()
+}
+
+func (e *editor) addProperty(name string, args ...string) {
+	e.doc.Properties = append(e.doc.Properties, md.Property{Name: name, Args: args, Pos: e.cursor()})
+	e.changed()
+}
+
+func (e *editor) toggleExit() {
+	pos := e.cursor()
+	if !e.doc.IsOnOuterWall(pos) {
+		e.message = "exit must be on the outer wall"
+		e.showStatus()
+		return
+	}
+
+	for i, v := range e.doc.Properties {
+		if v.Name == "exit" && v.Pos == pos {
+			e.doc.Properties = slices.Delete(e.doc.Properties, i, i+1)
+			e.changed()
+			return
+		}
+	}
+
+	e.addProperty("exit")
+}
+
+func (e *editor) nextWormholeIndex(system string) int {
+	res := 0
+	for y := 1; y <= e.doc.Height(); y++ {
+		for x := 1; x <= e.doc.Width(); x++ {
+			if strings.HasPrefix(e.doc.Code(lab.NewPosition(x, y)), "W:"+system+":") {
+				res++
+			}
+		}
+	}
+
+	return res
+}
+
+func (e *editor) placeWormhole() {
+	def := fmt.Sprintf("%v:%v", e.lastWormhole, e.nextWormholeIndex(e.lastWormhole))
+	e.ask("wormhole system:index", def, func(text string) {
+		system, _, _ := strings.Cut(text, ":")
+		e.lastWormhole = system
+		e.setCode("W:" + text)
+	})
+}
+
+func (e *editor) placePlayer() {
+	e.ask("player name[:team]", "", func(text string) {
+		name, team, _ := strings.Cut(text, ":")
+		if slices.Contains(reservedNames, name) {
+			e.message = fmt.Sprintf("`%v` can't be a player name", name)
+			e.showStatus()
+			return
+		}
+
+		if team != "" {
+			e.addProperty(name, team)
+		} else {
+			e.addProperty(name)
+		}
+	})
+}
+
+func (e *editor) resize(dw, dh int) {
+	e.doc.Resize(max(1, e.doc.Width()+dw), max(1, e.doc.Height()+dh))
+	e.changed()
+}
+
+func (e *editor) quit() {
+	if !e.saved && !e.quitArmed {
+		e.message = "the map is not saved, press q again to quit"
+		e.quitArmed = true
+		e.showStatus()
+		return
+	}
+
+	e.app.Stop()
+}
+
+func (e *editor) save() {
+	err := os.WriteFile(e.path, []byte(e.doc.String()), 0o644)
+	if err != nil {
+		e.message = err.Error()
+	} else {
+		e.saved = true
+	}
+	e.showStatus()
+}
+
+func (e *editor) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	e.message = ""
+	if event.Rune() != 'q' && event.Key() != tcell.KeyEscape {
+		e.quitArmed = false
+	}
+
+	switch event.Key() {
+	case tcell.KeyCtrlS:
+		e.save()
+		return nil
+	case tcell.KeyEscape:
+		e.quit()
+		return nil
+	case tcell.KeyRune:
+	default:
+		return event
+	}
+
+	switch event.Rune() {
+	case ' ':
+		e.setCode("")
+	case '#':
+		e.setCode("w")
+	case 'r':
+		e.setCode("R")
+	case 'm':
+		e.setCode("RM")
+	case '<':
+		e.setCode("←")
+	case '>':
+		e.setCode("→")
+	case '^':
+		e.setCode("↑")
+	case 'v':
+		e.setCode("↓")
+	case 'o':
+		e.placeWormhole()
+	case 'd':
+		e.ask("door key", "", func(text string) { e.setCode("D:" + text) })
+	case 'e':
+		e.toggleExit()
+	case 't':
+		e.ask("treasure points", "1", func(text string) { e.addProperty("treasure", text) })
+	case 'f':
+		e.addProperty("fake_treasure")
+	case 'k':
+		e.ask("key name", "", func(text string) { e.addProperty("key", text) })
+	case 'p':
+		e.placePlayer()
+	case 'M':
+		e.addProperty("minotaur")
+	case 'x':
+		e.doc.RemovePropertiesAt(e.cursor())
+		e.changed()
+	case '[':
+		e.resize(-1, 0)
+	case ']':
+		e.resize(1, 0)
+	case '{':
+		e.resize(0, -1)
+	case '}':
+		e.resize(0, 1)
+	case 'q':
+		e.quit()
+	default:
+		return event
+	}
+
+	return nil
+}
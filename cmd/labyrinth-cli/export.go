@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	lab "github.com/kepkin/labyrinth"
+	"github.com/kepkin/labyrinth/image"
+	"github.com/kepkin/labyrinth/replay"
+)
+
+func exportHTMLCmd(args []string) error {
+	opts := replay.HTMLOptions{}
+	out := ""
+	texturePack := ""
+
+	fs := flag.NewFlagSet("export-html", flag.ExitOnError)
+	fs.DurationVar(&opts.Delay, "delay", replay.DefaultDelay, "how long every turn is shown when the game plays")
+	fs.StringVar(&texturePack, "textures", "", "texture pack directory or manifest")
+	fs.StringVar(&out, "o", "game.html", "output file")
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	l, err := readGameLog(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	textures, err := loadTextures(texturePack)
+	if err != nil {
+		return err
+	}
+	opts.Base = func(cells *lab.CellMap) (image.Grid, error) {
+		return image.NewCellMapImage(cells, image.Options{Textures: textures, Marks: true})
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return replay.WriteHTML(f, l, opts)
+}
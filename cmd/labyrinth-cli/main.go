@@ -1,51 +1,266 @@
 package main
 
 import (
-	"image/jpeg"
+	"flag"
+	"fmt"
+	goimage "image"
+	"math/rand"
 	"os"
+	"strings"
+	"time"
 
 	lab "github.com/kepkin/labyrinth"
 	"github.com/kepkin/labyrinth/image"
 	md "github.com/kepkin/labyrinth/markdown"
+	"github.com/kepkin/labyrinth/replay"
+	"github.com/kepkin/labyrinth/strategy"
 )
 
-func main() {
-	if len(os.Args) != 2 {
-		panic("use: ./labyrinth map.md")
-	}
+const usage = `use:
+  labyrinth-cli [-fog] [-bot name=strategy]... [-rule name] [-turns n] [-textures dir]
+                [-format png] map.md
+                              play the game, players listed with -bot are played by
+                              a strategy: random or explorer. With -fog every player
+                              sees only what they know, for hot-seat play. -rule is
+                              first-out, most-points (ends after -turns moves) or
+                              last-survivor. The game is saved to game.log.
+                              -textures is a texture pack directory or manifest for
+                              the pictures, -format is png, png8, gif or jpeg
+  labyrinth-cli solve map.md  find the shortest way to win from every start position
+  labyrinth-cli report map.md compare difficulty of start positions
+  labyrinth-cli svg map.md    print the map as an SVG picture for printing
+  labyrinth-cli show [-ascii] map.md
+                              print the map as text with coordinates, players are
+                              marked with their numbers
+  labyrinth-cli print [-o kit.pdf] map.md
+                              make a PDF kit for the paper game: the master's map,
+                              a blank map for every player and a turn log
+  labyrinth-cli edit map.md   edit the map, a new map is created if the file doesn't exist
+  labyrinth-cli simulate [-games n] [-seed n] [-bot name=strategy]... [-rule name]
+                [-turns n] [-workers n] [-format csv|json] map.md
+                              play many games between bots and print statistics
+  labyrinth-cli replay [-player name] [-delay 500ms] [-textures dir]
+                [-o replay.gif] game.log
+                              make an animated GIF of a played game, with -player
+                              only the fog-of-war map of this player is shown
+  labyrinth-cli export-html [-delay 500ms] [-textures dir] [-o game.html] game.log
+                              make a web page of a played game: the map, a timeline
+                              of turns with what happened and the map of every player`
 
-	b, err := os.ReadFile(os.Args[1])
+func loadMap(path string) (*lab.World, []*lab.Player, error) {
+	b, err := os.ReadFile(path)
 	if err != nil {
-		panic(err.Error())
+		return nil, nil, err
 	}
+
+	return parseMap(string(b))
+}
+
+func parseMap(src string) (*lab.World, []*lab.Player, error) {
 	bb := md.WorldBuilder{
 		Cf: lab.CellWorldBuilder{
 			CellFac: lab.DefaultCellFactory,
 		},
 	}
-	w, pls, err := bb.Build(string(b))
+
+	return bb.Build(src)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "solve":
+		if len(os.Args) != 3 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		err = solve(os.Stdout, os.Args[2])
+	case "report":
+		if len(os.Args) != 3 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		err = report(os.Stdout, os.Args[2])
+	case "svg":
+		if len(os.Args) != 3 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		err = svgCmd(os.Stdout, os.Args[2])
+	case "show":
+		err = showCmd(os.Stdout, os.Args[2:])
+	case "print":
+		err = printCmd(os.Args[2:])
+	case "edit":
+		if len(os.Args) != 3 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		err = edit(os.Args[2])
+	case "simulate":
+		err = simulateCmd(os.Stdout, os.Args[2:])
+	case "replay":
+		err = replayCmd(os.Args[2:])
+	case "export-html":
+		err = exportHTMLCmd(os.Args[2:])
+	default:
+		opts := playOptions{Bots: botFlags{}}
+		fs := flag.NewFlagSet("play", flag.ExitOnError)
+		fs.Var(&opts.Bots, "bot", "seat played by a strategy, in format name=strategy")
+		fs.BoolVar(&opts.Fog, "fog", false, "show only what the current player knows")
+		fs.StringVar(&opts.Textures, "textures", "", "texture pack directory or manifest")
+		fs.StringVar(&opts.Format, "format", string(image.PNG), "format of pictures: png, png8, gif or jpeg")
+		fs.StringVar(&opts.Rule, "rule", "first-out", "victory rule: first-out, most-points or last-survivor")
+		fs.IntVar(&opts.MaxTurns, "turns", 0, "number of moves in a most-points game")
+		fs.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+		_ = fs.Parse(os.Args[1:])
+
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, usage)
+			os.Exit(2)
+		}
+		err = play(fs.Arg(0), opts)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+// botFlags collects -bot name=strategy flags
+type botFlags map[string]string
+
+func (b botFlags) String() string {
+	return fmt.Sprint(map[string]string(b))
+}
+
+func (b botFlags) Set(value string) error {
+	name, strategyName, _ := strings.Cut(value, "=")
+	if name == "" {
+		return fmt.Errorf("bot name is empty")
+	}
+
+	b[name] = strategyName
+	return nil
+}
+
+type playOptions struct {
+	// Bots are seats played by strategies
+	Bots     botFlags
+	Fog      bool
+	Textures string
+	Format   string
+	// Rule is the name of the victory rule, see lab.NewVictoryRule
+	Rule     string
+	MaxTurns int
+}
+
+func play(path string, opts playOptions) error {
+	format, err := image.ParseFormat(opts.Format)
 	if err != nil {
-		panic(err.Error())
+		return err
 	}
 
-	wimage, err := image.NewCellMapImage(&w.Cells)
+	rule, err := lab.NewVictoryRule(opts.Rule, opts.MaxTurns)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	f, err := os.Create("rendered.jpg")
+	src, err := os.ReadFile(path)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	err = jpeg.Encode(f, wimage, nil)
+
+	w, pls, err := parseMap(string(src))
 	if err != nil {
-		panic(err)
+		return err
 	}
 
+	textures, err := loadTextures(opts.Textures)
+	if err != nil {
+		return err
+	}
+
+	masterImage, err := image.NewCellMapImage(&w.Cells, image.Options{Textures: textures, Items: true, Marks: true})
+	if err != nil {
+		return err
+	}
+
+	err = writeImage("rendered"+format.Ext(), masterImage, format)
+	if err != nil {
+		return err
+	}
+
+	// trails draw items where they are at the end of the game
+	wimage, err := image.NewCellMapImage(&w.Cells, image.Options{Textures: textures, Marks: true})
+	if err != nil {
+		return err
+	}
+
+	seed := time.Now().UnixNano()
 	gameSession := &lab.Session{
 		World:   w,
 		Players: pls,
+		Rand:    rand.New(rand.NewSource(seed)),
+		Rule:    rule,
+	}
+	gameLog := replay.NewLog(string(src), seed, gameSession)
+	gameLog.Rule, gameLog.MaxTurns = opts.Rule, opts.MaxTurns
+
+	// bots have their own numbers, the session ones must stay the same when the game is replayed
+	botRand := rand.New(rand.NewSource(seed + 1))
+	var bots []*strategy.Bot
+	for name, strategyName := range opts.Bots {
+		if gameSession.FindPlayer(name) == nil {
+			return fmt.Errorf("there is no player %v on the map", name)
+		}
+
+		st, err := strategy.New(strategyName, botRand)
+		if err != nil {
+			return err
+		}
+		bots = append(bots, strategy.NewBot(name, st))
+	}
+
+	if len(bots) == len(pls) {
+		return fmt.Errorf("at least one player must be a human")
+	}
+
+	for _, p := range pls {
+		p.NewMap()
+	}
+
+	Run(gameSession, bots, opts.Fog)
+
+	gameLog.Record(gameSession)
+	if err := writeGameLog(gameLogPath, gameLog); err != nil {
+		return err
+	}
+
+	return writeImage("rendered-paths"+format.Ext(), image.NewTrails(wimage, gameSession), format)
+}
+
+// loadTextures reads the texture pack, the default one is used if the path is empty
+func loadTextures(path string) (*image.Textures, error) {
+	if path == "" {
+		return image.DefaultTextures()
+	}
+
+	return image.LoadTextures(path)
+}
+
+func writeImage(path string, img goimage.Image, format image.Format) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
 	}
+	defer f.Close()
 
-	Run(gameSession)
+	return image.Encode(f, img, format)
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kepkin/labyrinth/pdf"
+)
+
+func printCmd(args []string) error {
+	out := ""
+
+	fs := flag.NewFlagSet("print", flag.ExitOnError)
+	fs.StringVar(&out, "o", "kit.pdf", "output file")
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	w, pls, err := loadMap(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return pdf.WriteKit(f, w, pls)
+}
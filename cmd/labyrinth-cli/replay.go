@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	lab "github.com/kepkin/labyrinth"
+	"github.com/kepkin/labyrinth/image"
+	"github.com/kepkin/labyrinth/replay"
+)
+
+const gameLogPath = "game.log"
+
+func writeGameLog(path string, l *replay.Log) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return l.Write(f)
+}
+
+func readGameLog(path string) (*replay.Log, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return replay.ReadLog(f)
+}
+
+func replayCmd(args []string) error {
+	opts := replay.Options{}
+	out := ""
+	texturePack := ""
+
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	fs.DurationVar(&opts.Delay, "delay", replay.DefaultDelay, "how long every turn is shown")
+	fs.StringVar(&opts.Player, "player", "", "show the fog-of-war map of this player instead of the full map")
+	fs.StringVar(&texturePack, "textures", "", "texture pack directory or manifest")
+	fs.StringVar(&out, "o", "replay.gif", "output file")
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	l, err := readGameLog(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	textures, err := loadTextures(texturePack)
+	if err != nil {
+		return err
+	}
+	opts.Base = func(cells *lab.CellMap) (image.Grid, error) {
+		// a player's replay shows only what they saw
+		return image.NewCellMapImage(cells, image.Options{Textures: textures, Marks: opts.Player == ""})
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return replay.WriteGIF(f, l, opts)
+}
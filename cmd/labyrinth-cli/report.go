@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/kepkin/labyrinth/solver"
+)
+
+func report(out io.Writer, path string) error {
+	w, pls, err := loadMap(path)
+	if err != nil {
+		return err
+	}
+
+	r := solver.NewReport(w, pls)
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "player\tstart\tto treasure\tto exit\ttotal\triver drags\tteleports\treachable\tadvantage")
+	for _, v := range r.Starts {
+		if !v.Solvable {
+			fmt.Fprintf(tw, "%v\t%v\tunsolvable\t\t\t\t\t%v\t\n", v.Player, v.Start, v.Reachable)
+			continue
+		}
+
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%+.0f%%\n",
+			v.Player, v.Start, v.ToTreasure, v.ToExit, v.Moves(), v.RiverDrags, v.Teleports, v.Reachable, v.Advantage*100)
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "\nfairness: %.2f\n", r.Fairness)
+	return err
+}
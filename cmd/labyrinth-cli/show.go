@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kepkin/labyrinth/text"
+)
+
+func showCmd(out io.Writer, args []string) error {
+	ascii := false
+
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	fs.BoolVar(&ascii, "ascii", false, "use plain ASCII characters")
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	w, pls, err := loadMap(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	opts := text.Options{Style: text.Unicode, Markers: text.PlayerMarkers(pls), Rulers: true}
+	if ascii {
+		opts.Style = text.ASCII
+	}
+
+	err = text.Write(out, &w.Cells, opts)
+	if err != nil {
+		return err
+	}
+
+	for i, pl := range pls {
+		_, err = fmt.Fprintf(out, "%c %v %v\n", text.PlayerMarker(i), pl.Name, pl.Pos)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
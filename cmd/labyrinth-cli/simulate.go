@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	lab "github.com/kepkin/labyrinth"
+	"github.com/kepkin/labyrinth/simulate"
+)
+
+func simulateCmd(out io.Writer, args []string) error {
+	bots := botFlags{}
+	cfg := simulate.Config{}
+	format := ""
+
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	fs.Var(&bots, "bot", "strategy of a player, in format name=strategy. Other players are explorers")
+	fs.IntVar(&cfg.Games, "games", 1000, "number of games")
+	fs.Int64Var(&cfg.FirstSeed, "seed", 1, "seed of the first game, next games use next seeds")
+	fs.IntVar(&cfg.MaxTurns, "turns", simulate.DefaultMaxTurns, "game without a winner after this number of moves")
+	fs.StringVar(&cfg.Rule, "rule", "first-out", "victory rule: first-out, most-points or last-survivor")
+	fs.IntVar(&cfg.Workers, "workers", 0, "games played in parallel, one per CPU by default")
+	fs.StringVar(&format, "format", "csv", "output format: csv or json")
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("unknown format `%v`", format)
+	}
+
+	src, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	_, pls, err := parseMap(string(src))
+	if err != nil {
+		return err
+	}
+	for name := range bots {
+		if (&lab.Session{Players: pls}).FindPlayer(name) == nil {
+			return fmt.Errorf("there is no player %v on the map", name)
+		}
+	}
+	cfg.Strategies = bots
+
+	games, err := simulate.Run(func() (*lab.World, []*lab.Player, error) { return parseMap(string(src)) }, cfg)
+	if err != nil {
+		return err
+	}
+
+	stats := simulate.NewStats(cfg, games)
+	if format == "json" {
+		return stats.WriteJSON(out)
+	}
+
+	return stats.WriteCSV(out)
+}
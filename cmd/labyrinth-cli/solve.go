@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kepkin/labyrinth/solver"
+)
+
+func solve(out io.Writer, path string) error {
+	w, pls, err := loadMap(path)
+	if err != nil {
+		return err
+	}
+
+	s := solver.New(w)
+	for _, p := range pls {
+		sol, err := s.Solve(p.Pos)
+		if err != nil {
+			fmt.Fprintf(out, "%v (%v): %v\n", p.Name, p.Pos, err)
+			continue
+		}
+
+		fmt.Fprintf(out, "%v (%v): %v moves, %v cells reachable\n", p.Name, p.Pos, sol.Moves(), len(s.Reachable(p.Pos)))
+		fmt.Fprintf(out, "  to treasure: %v\n", joinActions(sol.ToTreasure))
+		fmt.Fprintf(out, "  to exit:     %v\n", joinActions(sol.ToExit))
+	}
+
+	return nil
+}
+
+func joinActions(steps []solver.Step) string {
+	res := make([]string, 0, len(steps))
+	for _, v := range steps {
+		res = append(res, v.Action)
+	}
+
+	return strings.Join(res, ", ")
+}
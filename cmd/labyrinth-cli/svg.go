@@ -0,0 +1,16 @@
+package main
+
+import (
+	"io"
+
+	"github.com/kepkin/labyrinth/svg"
+)
+
+func svgCmd(out io.Writer, path string) error {
+	w, _, err := loadMap(path)
+	if err != nil {
+		return err
+	}
+
+	return svg.Write(out, &w.Cells, svg.Options{})
+}
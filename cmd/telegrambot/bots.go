@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	lab "github.com/kepkin/labyrinth"
+	"github.com/kepkin/labyrinth/strategy"
+)
+
+type AddBotState struct {
+	SessionID string
+}
+
+func (s *AddBotState) handleIncorrectFormat(ctx context.Context, b *bot.Bot, update *models.Update) {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: update.Message.Chat.ID,
+		Text:   "Inccorecct format. Write /addbot <random|explorer> X:Y (Example: /addbot explorer 1:3)",
+	})
+
+	if err != nil {
+		log.Print(err.Error())
+	}
+}
+
+func (s *AddBotState) Handle(ctx context.Context, b *bot.Bot, update *models.Update) {
+	args := strings.Fields(strings.TrimPrefix(update.Message.Text, "/addbot"))
+	if len(args) != 2 {
+		s.handleIncorrectFormat(ctx, b, update)
+		return
+	}
+
+	st, err := strategy.New(args[0], rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		s.handleIncorrectFormat(ctx, b, update)
+		return
+	}
+
+	posValues := strings.Split(args[1], ":")
+	if len(posValues) != 2 {
+		s.handleIncorrectFormat(ctx, b, update)
+		return
+	}
+	x, err := strconv.Atoi(posValues[0])
+	if err != nil {
+		s.handleIncorrectFormat(ctx, b, update)
+		return
+	}
+	y, err := strconv.Atoi(posValues[1])
+	if err != nil {
+		s.handleIncorrectFormat(ctx, b, update)
+		return
+	}
+
+	sess, err := sessionRepository.FindSession(s.SessionID)
+	if err != nil {
+		log.Default().Println(err)
+	}
+	if sess == nil || sess.Started {
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   "You can add bots only before the game starts",
+		})
+
+		if err != nil {
+			log.Print(err.Error())
+		}
+		return
+	}
+
+	sess.mu.Lock()
+	if !strategy.HasHumans(&sess.GameSession, sess.Bots) {
+		sess.mu.Unlock()
+
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   "Join the game before adding bots, bots don't play alone",
+		})
+
+		if err != nil {
+			log.Print(err.Error())
+		}
+		return
+	}
+
+	name := fmt.Sprintf("bot-%v", len(sess.Bots)+1)
+	sess.GameSession.AddPlayer(name, lab.NewPosition(x, y))
+	sess.Bots = append(sess.Bots, strategy.NewBot(name, st))
+	sess.mu.Unlock()
+
+	sess.broadcast(ctx, b, fmt.Sprintf("%v (%v) joined", name, args[0]))
+}
+
+func eventsText(evs []lab.Event) string {
+	eventStringer := lab.DefaultEventStringer{}
+
+	msg := strings.Builder{}
+	for _, event := range evs {
+		msg.WriteString(eventStringer.ToString(event))
+		msg.WriteString("\n")
+	}
+
+	return msg.String()
+}
+
+// finish ends the game for all users and sends them the replay. It must be called with s.mu locked
+func (s *MemSession) finish(ctx context.Context, b *bot.Bot) {
+	if s.Timer != nil {
+		s.Timer.Stop()
+	}
+	s.sendReplay(ctx, b)
+
+	for _, x := range s.Users {
+		userStateRepository.SetUserState(x.ID, &JoinState{})
+	}
+
+	if len(s.Users) > 0 {
+		sessionRepository.StopSession(s.Users[0].ID)
+	}
+}
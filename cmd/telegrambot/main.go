@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-telegram/bot"
@@ -17,6 +18,8 @@ import (
 	lab "github.com/kepkin/labyrinth"
 	"github.com/kepkin/labyrinth/image"
 	md "github.com/kepkin/labyrinth/markdown"
+	"github.com/kepkin/labyrinth/replay"
+	"github.com/kepkin/labyrinth/strategy"
 )
 
 // Send any text message to the bot after the bot has been started
@@ -54,6 +57,33 @@ type MemSession struct {
 
 	Started     bool
 	GameSession lab.Session
+	Timer       *lab.TurnTimer
+	Bots        []*strategy.Bot
+	// Rule is the name of the victory rule chosen before the game, see lab.NewVictoryRule
+	Rule     string
+	MaxTurns int
+	// Mode is how players take turns, orders of a round are waited for turnTimeout in lab.SimultaneousTurns
+	Mode lab.TurnMode
+	// Log records the game to send its replay at the end
+	Log *replay.Log
+	// canvases keep the map picture of every player, so a move redraws only what the player has learnt
+	canvases map[string]*image.Canvas
+
+	mu sync.Mutex
+}
+
+func (s *MemSession) canvas(name string) *image.Canvas {
+	if s.canvases == nil {
+		s.canvases = map[string]*image.Canvas{}
+	}
+
+	res, ok := s.canvases[name]
+	if !ok {
+		res = image.NewCanvas(makeCellMapImage(false))
+		s.canvases[name] = res
+	}
+
+	return res
 }
 
 func (s *MemSession) Join(user TgUser, p lab.Position) error {
@@ -70,21 +100,52 @@ var sessionRepository SessionRepository
 var userStateRepository UserStateRepository
 
 var _world *lab.World
-var _cellMapImage *image.CellMap
+var loadTextures = sync.OnceValues(func() (*image.Textures, error) {
+	if pack := os.Getenv("LABYRINTH_TEXTURES"); pack != "" {
+		return image.LoadTextures(pack)
+	}
 
-func makeCellMapImage() *image.CellMap {
-	if _cellMapImage != nil {
-		return _cellMapImage
+	return image.DefaultTextures()
+})
+
+// textures are the pack from LABYRINTH_TEXTURES or the default one
+func textures() *image.Textures {
+	res, err := loadTextures()
+	if err != nil {
+		panic(err)
 	}
 
-	var err error
+	return res
+}
+
+// cellMapImages are built once and shared by all sessions, with marks and without
+var cellMapImages = map[bool]func() (*image.CellMap, error){
+	true:  sync.OnceValues(func() (*image.CellMap, error) { return newCellMapImage(true) }),
+	false: sync.OnceValues(func() (*image.CellMap, error) { return newCellMapImage(false) }),
+}
+
+func newCellMapImage(marks bool) (*image.CellMap, error) {
 	w := makeWorld()
-	_cellMapImage, err = image.NewCellMapImage(&w.Cells)
+	return image.NewCellMapImage(&w.Cells, image.Options{Textures: textures(), Marks: marks})
+}
+
+// makeCellMapImage draws the map, players see it without marks until the game is over
+func makeCellMapImage(marks bool) *image.CellMap {
+	res, err := cellMapImages[marks]()
 	if err != nil {
 		panic(err)
 	}
 
-	return _cellMapImage
+	return res
+}
+
+func mapSource() string {
+	worldBytes, err := os.ReadFile("./examples/lab-map1.md")
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return string(worldBytes)
 }
 
 func makeWorld() *lab.World {
@@ -92,16 +153,13 @@ func makeWorld() *lab.World {
 		return _world
 	}
 
-	worldBytes, err := os.ReadFile("./examples/lab-map1.md")
-	if err != nil {
-		panic(err.Error())
-	}
+	// every game gets a fresh factory, so it's built the same way as its replay
 	bb := md.WorldBuilder{
 		Cf: lab.CellWorldBuilder{
-			CellFac: lab.DefaultCellFactory,
+			CellFac: lab.NewDefaultCellFactory(),
 		},
 	}
-	_world, _, err := bb.Build(string(worldBytes))
+	_world, _, err := bb.Build(mapSource())
 	if err != nil {
 		panic(err.Error())
 	}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	lab "github.com/kepkin/labyrinth"
+	"github.com/kepkin/labyrinth/image"
+	"github.com/kepkin/labyrinth/strategy"
+)
+
+// announceRound starts a round of a simultaneous game: bots send their orders, humans are asked for theirs and
+// the round deadline is armed. It must be called with s.mu locked
+func (s *MemSession) announceRound(ctx context.Context, b *bot.Bot) {
+	if s.Timer == nil {
+		s.Timer = lab.NewTurnTimer(lab.SystemClock{}, turnTimeout)
+	}
+
+	strategy.SubmitOrders(&s.GameSession, s.Bots)
+	s.GameSession.StartRound(s.Timer.Clock.Now())
+
+	for _, x := range s.Users {
+		p := s.GameSession.FindPlayer(x.Username)
+		if p == nil || p.Dead {
+			continue
+		}
+
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:      x.ID,
+			Text:        fmt.Sprintf("Round %v, send your move. You have %v", s.GameSession.Turn()+1, turnTimeout),
+			ReplyMarkup: getInGameMoveReplyKeyboard(s.GameSession.PossibleActions(p)),
+		})
+
+		if err != nil {
+			log.Print(err.Error())
+		}
+	}
+
+	s.Timer.Reset(nil, func() {
+		s.onRoundTimeout(ctx, b)
+	})
+}
+
+// order takes the order of the user for the round and resolves the round when everyone has sent theirs.
+// It must be called with s.mu locked
+func (s *MemSession) order(ctx context.Context, b *bot.Bot, user TgUser, action string) {
+	text := "Your move is accepted, waiting for the others"
+	if err := s.GameSession.Submit(user.Username, action); err != nil {
+		text = err.Error()
+	}
+
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: user.ID,
+		Text:   text,
+	})
+
+	if err != nil {
+		log.Print(err.Error())
+	}
+
+	if s.GameSession.RoundReady() {
+		s.finishRound(ctx, b, s.GameSession.ResolveRound())
+	}
+}
+
+func (s *MemSession) onRoundTimeout(ctx context.Context, b *bot.Bot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the round may have been resolved while the timer was waiting for the lock
+	if s.GameSession.IsOver() || !s.GameSession.RoundExpired(s.Timer.Clock.Now()) {
+		return
+	}
+
+	s.finishRound(ctx, b, s.GameSession.AutoSkip())
+}
+
+// finishRound tells everyone what happened in the round and shows them their maps, then starts the next round.
+// It must be called with s.mu locked
+func (s *MemSession) finishRound(ctx context.Context, b *bot.Bot, evs []lab.Event) {
+	strategy.Broadcast(s.Bots, evs...)
+	s.removeIdleUsers(evs)
+	// bots alone would play forever, nobody is left to watch them
+	isOver := s.GameSession.IsOver() || !strategy.HasHumans(&s.GameSession, s.Bots)
+
+	paths := bytes.NewBuffer(nil)
+	if isOver {
+		err := image.Encode(paths, image.NewTrails(makeCellMapImage(true), &s.GameSession), image.PNG)
+		if err != nil {
+			log.Print(err)
+		}
+	}
+
+	eventStringer := lab.DefaultEventStringer{}
+	for _, x := range s.Users {
+		msg := strings.Builder{}
+		msg.WriteString(fmt.Sprintf("Round %v is over", s.GameSession.Turn()))
+		for _, event := range evs {
+			if event.Type == lab.RoundEndEventType {
+				continue
+			}
+			msg.WriteString("\n \\- ")
+			msg.WriteString(eventStringer.ToString(event))
+		}
+
+		var picture []byte
+		if p := s.GameSession.FindPlayer(x.Username); p != nil {
+			picture = s.playerMaps(&msg, p)
+		}
+
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    x.ID,
+			Text:      msg.String(),
+			ParseMode: models.ParseModeMarkdown,
+		})
+		if err != nil {
+			log.Print(err.Error())
+		}
+
+		if picture != nil {
+			_, err = b.SendPhoto(ctx, &bot.SendPhotoParams{
+				ChatID: x.ID,
+				Photo: &models.InputFileUpload{
+					Filename: "map.png",
+					Data:     bytes.NewReader(picture),
+				},
+				Caption: "map",
+			})
+			if err != nil {
+				log.Print(err.Error())
+			}
+		}
+
+		if isOver {
+			sendPaths(ctx, b, x.ID, paths.Bytes())
+		}
+	}
+
+	if isOver {
+		if !s.GameSession.IsOver() {
+			s.broadcast(ctx, b, "Only bots are left, the game is over")
+		}
+		s.finish(ctx, b)
+		return
+	}
+
+	s.announceRound(ctx, b)
+}
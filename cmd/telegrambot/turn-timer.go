@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-telegram/bot"
+
+	lab "github.com/kepkin/labyrinth"
+	"github.com/kepkin/labyrinth/strategy"
+)
+
+const turnTimeout = 2 * time.Minute
+const maxIdleSkips = 3
+
+func (s *MemSession) findUser(name string) (TgUser, bool) {
+	for _, x := range s.Users {
+		if x.Username == name {
+			return x, true
+		}
+	}
+
+	return TgUser{}, false
+}
+
+func (s *MemSession) broadcast(ctx context.Context, b *bot.Bot, text string) {
+	for _, x := range s.Users {
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: x.ID,
+			Text:   text,
+		})
+
+		if err != nil {
+			log.Print(err.Error())
+		}
+	}
+}
+
+// armTurnTimer starts the turn timer for the current player. It must be called with s.mu locked
+func (s *MemSession) armTurnTimer(ctx context.Context, b *bot.Bot) {
+	if s.Timer == nil {
+		s.Timer = lab.NewTurnTimer(lab.SystemClock{}, turnTimeout)
+	}
+
+	if s.GameSession.IsOver() || len(s.GameSession.Players) == 0 {
+		s.Timer.Stop()
+		return
+	}
+
+	pl := s.GameSession.GetCurrentPlayer()
+	turn := s.GameSession.Turn()
+	s.Timer.Reset(
+		func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			if !s.isArmedTurn(turn, pl.Name) {
+				return
+			}
+
+			user, ok := s.findUser(pl.Name)
+			if !ok {
+				return
+			}
+
+			_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: user.ID,
+				Text:   fmt.Sprintf("Hurry up, you have %v left to make a move", turnTimeout/2),
+			})
+
+			if err != nil {
+				log.Print(err.Error())
+			}
+		},
+		func() {
+			s.onTurnTimeout(ctx, b, turn, pl.Name)
+		},
+	)
+}
+
+// isArmedTurn tells if it's still the turn the timer was armed for. It must be called with s.mu locked
+func (s *MemSession) isArmedTurn(turn int, name string) bool {
+	return !s.GameSession.IsOver() && len(s.GameSession.Players) > 0 &&
+		s.GameSession.Turn() == turn && s.GameSession.GetCurrentPlayer().Name == name
+}
+
+// onTurnTimeout skips the turn of the player, if it's still the turn the timer was armed for
+func (s *MemSession) onTurnTimeout(ctx context.Context, b *bot.Bot, turn int, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the player may have moved while the timer was waiting for the lock
+	if !s.isArmedTurn(turn, name) {
+		return
+	}
+
+	evs := s.GameSession.AutoSkip()
+	strategy.Broadcast(s.Bots, evs...)
+	s.removeIdleUsers(evs)
+	s.broadcast(ctx, b, eventsText(evs))
+
+	if s.GameSession.IsOver() || len(s.GameSession.Players) == 0 {
+		s.finish(ctx, b)
+		return
+	}
+
+	s.announceTurn(ctx, b)
+}
+
+// removeIdleUsers takes users whose players were removed for inactivity out of the session
+func (s *MemSession) removeIdleUsers(evs []lab.Event) {
+	for _, event := range evs {
+		if event.Type != lab.PlayerRemovedEventType {
+			continue
+		}
+
+		if user, ok := s.findUser(event.Subject); ok {
+			err := sessionRepository.RemoveUserFromSession(user.ID)
+			if err != nil {
+				log.Print(err.Error())
+			}
+			userStateRepository.SetUserState(user.ID, nil)
+		}
+	}
+}
+
+// announceTurn tells everyone whose turn it is and arms the turn timer. It must be called with s.mu locked
+func (s *MemSession) announceTurn(ctx context.Context, b *bot.Bot) {
+	if evs := strategy.PlayBots(&s.GameSession, s.Bots); len(evs) > 0 {
+		s.broadcast(ctx, b, eventsText(evs))
+	}
+
+	if s.GameSession.IsOver() {
+		s.finish(ctx, b)
+		return
+	}
+
+	// bots alone would play forever, nobody is left to watch them
+	if !strategy.HasHumans(&s.GameSession, s.Bots) {
+		s.broadcast(ctx, b, "Only bots are left, the game is over")
+		s.finish(ctx, b)
+		return
+	}
+
+	nextPl := s.GameSession.GetCurrentPlayer()
+	for _, x := range s.Users {
+		if x.Username == nextPl.Name {
+			s.askMove(ctx, b, x)
+			continue
+		}
+
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: x.ID,
+			Text:   fmt.Sprintf("%v turn", nextPl.Name),
+		})
+
+		if err != nil {
+			log.Print(err.Error())
+		}
+	}
+
+	s.armTurnTimer(ctx, b)
+}
+
+// askMove sends the current player the actions they have. It must be called with s.mu locked
+func (s *MemSession) askMove(ctx context.Context, b *bot.Bot, user TgUser) {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      user.ID,
+		Text:        "Your turn",
+		ReplyMarkup: getInGameMoveReplyKeyboard(s.GameSession.GetCurrentPlayerPossibleActions()),
+	})
+
+	if err != nil {
+		log.Print(err.Error())
+	}
+}
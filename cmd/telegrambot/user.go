@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"image/jpeg"
+	goimage "image"
 	"log"
+	"math/rand"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,6 +19,9 @@ import (
 	lru "github.com/hashicorp/golang-lru/v2/expirable"
 	lab "github.com/kepkin/labyrinth"
 	"github.com/kepkin/labyrinth/image"
+	"github.com/kepkin/labyrinth/replay"
+	"github.com/kepkin/labyrinth/strategy"
+	"github.com/kepkin/labyrinth/text"
 	labtv "github.com/kepkin/labyrinth/tview"
 )
 
@@ -169,7 +174,9 @@ func (s *ExitState) Handle(ctx context.Context, b *bot.Bot, update *models.Updat
 		log.Default().Println(err)
 	}
 	if sess != nil {
+		sess.mu.Lock()
 		sess.GameSession.RemovePlayer(user.Username)
+		sess.mu.Unlock()
 		for _, x := range sess.Users {
 			_, err := b.SendMessage(ctx, &bot.SendMessageParams{
 				ChatID: x.ID,
@@ -228,15 +235,19 @@ func (s *ChoosePositionState) Handle(ctx context.Context, b *bot.Bot, update *mo
 
 	userStateRepository.SetUserState(user.ID, &BaseRouteState{
 		Route: map[string]UserState{
-			"":     &WaitForGameStartState{SessionID: s.SessionID},
-			"info": &InfoState{SessionID: s.SessionID},
+			"":        &WaitForGameStartState{SessionID: s.SessionID},
+			"info":    &InfoState{SessionID: s.SessionID},
+			"/team":   &ChooseTeamState{SessionID: s.SessionID},
+			"/rule":   &ChooseRuleState{SessionID: s.SessionID},
+			"/mode":   &ChooseModeState{SessionID: s.SessionID},
+			"/addbot": &AddBotState{SessionID: s.SessionID},
 		},
 	})
 
 	for _, x := range sess.Users {
 		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID: x.ID,
-			Text:   fmt.Sprintf("%v joined", user.Username),
+			Text:   fmt.Sprintf("%v joined. To play in a team write /team <name>, to add a bot write /addbot <random|explorer> X:Y, to change how the game is won write /rule <first-out|most-points N|last-survivor>, to move all at once write /mode simultaneous", user.Username),
 		})
 
 		if err != nil {
@@ -246,6 +257,152 @@ func (s *ChoosePositionState) Handle(ctx context.Context, b *bot.Bot, update *mo
 	}
 }
 
+type ChooseTeamState struct {
+	SessionID string
+}
+
+func (s *ChooseTeamState) Handle(ctx context.Context, b *bot.Bot, update *models.Update) {
+	user := NewTgUserFromUpdate(update)
+	team := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/team"))
+
+	sess, err := sessionRepository.FindSession(s.SessionID)
+	if err != nil {
+		log.Default().Println(err)
+	}
+	if sess == nil {
+		return
+	}
+
+	sess.mu.Lock()
+	reply := "You can choose a team only before the game starts"
+	if !sess.Started {
+		reply = ""
+		if err := sess.GameSession.SetPlayerTeam(user.Username, team); err != nil {
+			reply = fmt.Sprintf("Can't choose the team: %v", err)
+		}
+	}
+	sess.mu.Unlock()
+
+	if reply != "" {
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   reply,
+		})
+
+		if err != nil {
+			log.Print(err.Error())
+		}
+		return
+	}
+
+	text := fmt.Sprintf("%v plays alone", user.Username)
+	if team != "" {
+		text = fmt.Sprintf("%v joined team %v", user.Username, team)
+	}
+
+	sess.broadcast(ctx, b, text)
+}
+
+type ChooseRuleState struct {
+	SessionID string
+}
+
+func (s *ChooseRuleState) Handle(ctx context.Context, b *bot.Bot, update *models.Update) {
+	args := strings.Fields(strings.TrimPrefix(update.Message.Text, "/rule"))
+
+	sess, err := sessionRepository.FindSession(s.SessionID)
+	if err != nil {
+		log.Default().Println(err)
+	}
+	if sess == nil || sess.Started {
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   "You can choose the rule only before the game starts",
+		})
+
+		if err != nil {
+			log.Print(err.Error())
+		}
+		return
+	}
+
+	name, maxTurns := "", 0
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if len(args) > 1 {
+		maxTurns, err = strconv.Atoi(args[1])
+	}
+	if err == nil {
+		_, err = lab.NewVictoryRule(name, maxTurns)
+	}
+	if err != nil || len(args) == 0 || len(args) > 2 {
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   "Inccorecct format. Write /rule first-out, /rule most-points <turns> or /rule last-survivor",
+		})
+
+		if err != nil {
+			log.Print(err.Error())
+		}
+		return
+	}
+
+	sess.mu.Lock()
+	sess.Rule, sess.MaxTurns = name, maxTurns
+	sess.mu.Unlock()
+
+	sess.broadcast(ctx, b, fmt.Sprintf("%v set the rule: %v", update.Message.From.Username, strings.Join(args, " ")))
+}
+
+type ChooseModeState struct {
+	SessionID string
+}
+
+func (s *ChooseModeState) Handle(ctx context.Context, b *bot.Bot, update *models.Update) {
+	name := strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/mode"))
+
+	sess, err := sessionRepository.FindSession(s.SessionID)
+	if err != nil {
+		log.Default().Println(err)
+	}
+	if sess == nil || sess.Started {
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   "You can choose the mode only before the game starts",
+		})
+
+		if err != nil {
+			log.Print(err.Error())
+		}
+		return
+	}
+
+	var mode lab.TurnMode
+	switch name {
+	case "turns":
+		mode = lab.RoundRobinTurns
+	case "simultaneous":
+		mode = lab.SimultaneousTurns
+	default:
+		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   "Inccorecct format. Write /mode turns or /mode simultaneous",
+		})
+
+		if err != nil {
+			log.Print(err.Error())
+		}
+		return
+	}
+
+	sess.mu.Lock()
+	sess.Mode = mode
+	sess.mu.Unlock()
+
+	sess.broadcast(ctx, b, fmt.Sprintf("%v set the mode: %v", update.Message.From.Username, name))
+}
+
 type WaitForGameStartState struct {
 	SessionID string
 }
@@ -257,6 +414,24 @@ func (s *WaitForGameStartState) Handle(ctx context.Context, b *bot.Bot, update *
 		if err != nil {
 			log.Default().Println(err)
 		}
+		if sess == nil {
+			return
+		}
+
+		sess.mu.Lock()
+		hasHumans := strategy.HasHumans(&sess.GameSession, sess.Bots)
+		sess.mu.Unlock()
+		if !hasHumans {
+			_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+				ChatID: update.Message.Chat.ID,
+				Text:   "A game needs at least one human player",
+			})
+
+			if err != nil {
+				log.Print(err.Error())
+			}
+			return
+		}
 
 		w := makeWorld()
 		sess.GameSession.World = w
@@ -265,8 +440,26 @@ func (s *WaitForGameStartState) Handle(ctx context.Context, b *bot.Bot, update *
 			labtv.RunDebug(w, &sess.GameSession)
 		}()
 
-		pl := sess.GameSession.GetCurrentPlayer()
-		nextTgUser := TgUser{}
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+
+		sess.GameSession.MaxIdleSkips = maxIdleSkips
+		// the rule has been checked when it was chosen
+		sess.GameSession.Rule, _ = lab.NewVictoryRule(sess.Rule, sess.MaxTurns)
+		for _, p := range sess.GameSession.Players {
+			p.NewMap()
+		}
+
+		seed := time.Now().UnixNano()
+		sess.GameSession.Rand = rand.New(rand.NewSource(seed))
+		sess.GameSession.Mode = sess.Mode
+		sess.GameSession.RoundDeadline = turnTimeout
+		// only round robin games can be replayed
+		if sess.Mode == lab.RoundRobinTurns {
+			sess.Log = replay.NewLog(mapSource(), seed, &sess.GameSession)
+			sess.Log.Rule, sess.Log.MaxTurns = sess.Rule, sess.MaxTurns
+		}
+
 		for _, x := range sess.Users {
 			userStateRepository.SetUserState(x.ID, &BaseRouteState{
 				Route: map[string]UserState{
@@ -274,31 +467,14 @@ func (s *WaitForGameStartState) Handle(ctx context.Context, b *bot.Bot, update *
 					"/exit": &ExitState{},
 				},
 			})
-			pl.NewMap()
-
-			if pl.Name == x.Username {
-				nextTgUser = x
-			} else {
-				_, err := b.SendMessage(ctx, &bot.SendMessageParams{
-					ChatID: x.ID,
-					Text:   fmt.Sprintf("Game started. %v move", pl.Name),
-				})
-
-				if err != nil {
-					log.Print(err.Error())
-				}
-			}
 		}
 
-		_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-			ChatID:      nextTgUser.ID,
-			Text:        "Your turn",
-			ReplyMarkup: getInGameMoveReplyKeyboard(sess.GameSession.GetCurrentPlayerPossibleActions()),
-		})
-
-		if err != nil {
-			log.Print(err.Error())
+		sess.broadcast(ctx, b, "Game started")
+		if sess.Mode == lab.SimultaneousTurns {
+			sess.announceRound(ctx, b)
+			return
 		}
+		sess.announceTurn(ctx, b)
 	}
 }
 
@@ -390,6 +566,18 @@ func (s *InGameCommandState) Handle(ctx context.Context, b *bot.Bot, update *mod
 		log.Default().Println(err)
 	}
 
+	if sess == nil {
+		return
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.GameSession.Mode == lab.SimultaneousTurns {
+		sess.order(ctx, b, user, update.Message.Text)
+		return
+	}
+
 	pl := sess.GameSession.GetCurrentPlayer()
 	if pl.Name != user.Username {
 		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
@@ -400,70 +588,34 @@ func (s *InGameCommandState) Handle(ctx context.Context, b *bot.Bot, update *mod
 		if err != nil {
 			log.Print(err.Error())
 		}
+		return
 	}
 
 	eventStringer := lab.DefaultEventStringer{}
 
 	move := update.Message.Text
+	turn := sess.GameSession.Turn()
 	evs := sess.GameSession.Do(move)
-	nextPl := sess.GameSession.GetCurrentPlayer()
+	strategy.Broadcast(sess.Bots, evs...)
 	msg := strings.Builder{}
 	msg.WriteString(fmt.Sprintf("Player %v made a move %v", pl.Name, move))
 
-	isWin := false
 	for _, event := range evs {
 		msg.WriteString("\n \\- ")
 		msg.WriteString(eventStringer.ToString(event))
-
-		if event.Type == lab.WinEventType {
-			isWin = true
-		}
 	}
-	msg.WriteString("\n\n```\n")
+	isOver := sess.GameSession.IsOver()
 
-	lastRow := 0
-	for p, c := range sess.GameSession.World.Cells.Rect(pl.Map.LeftCorner, pl.Map.RightCorner) {
-		if p.Y > lastRow {
-			msg.WriteString("\n")
-			lastRow = p.Y
-		}
-
-		_, ok := pl.Map.KnonwnCells[p]
-		if !ok {
-			msg.WriteString(" ")
-			continue
-		}
-
-		switch c.Class {
-		case lab.CellEarth:
-			msg.WriteString("e")
-
-		case lab.CellRiver:
-			msg.WriteString("r")
+	picture := sess.playerMaps(&msg, pl)
 
-		case lab.CellWall:
-			msg.WriteString("w")
-
-		case lab.CellWormHole:
-			msg.WriteString("o")
+	paths := bytes.NewBuffer(nil)
+	if isOver {
+		err = image.Encode(paths, image.NewTrails(makeCellMapImage(true), &sess.GameSession), image.PNG)
+		if err != nil {
+			log.Print(err)
 		}
-
-	}
-	msg.WriteString("\n```")
-
-	ipm := image.NewPlayerMap(makeCellMapImage(), &(pl.Map))
-
-	f := bytes.NewBuffer(nil)
-	if err != nil {
-		log.Print(err)
-	}
-
-	err = jpeg.Encode(f, ipm, nil)
-	if err != nil {
-		log.Print(err)
 	}
 
-	nextTgUser := TgUser{}
 	for _, x := range sess.Users {
 		_, err := b.SendMessage(ctx, &bot.SendMessageParams{
 			ChatID:    x.ID,
@@ -477,8 +629,8 @@ func (s *InGameCommandState) Handle(ctx context.Context, b *bot.Bot, update *mod
 		params := &bot.SendPhotoParams{
 			ChatID: x.ID,
 			Photo: &models.InputFileUpload{
-				Filename: "example.gif",
-				Data:     bytes.NewReader(f.Bytes()),
+				Filename: "map.png",
+				Data:     bytes.NewReader(picture),
 			},
 			Caption: "map",
 		}
@@ -489,34 +641,89 @@ func (s *InGameCommandState) Handle(ctx context.Context, b *bot.Bot, update *mod
 			log.Print(err.Error())
 		}
 
-		if isWin {
+		if isOver {
+			sendPaths(ctx, b, x.ID, paths.Bytes())
 			userStateRepository.SetUserState(x.ID, &JoinState{})
-			continue
 		}
+	}
 
-		if x.Username == nextPl.Name {
-			nextTgUser = x
-		} else {
-			_, err := b.SendMessage(ctx, &bot.SendMessageParams{
-				ChatID: x.ID,
-				Text:   fmt.Sprintf("%v turn", nextPl.Name),
-			})
+	if isOver {
+		if sess.Timer != nil {
+			sess.Timer.Stop()
+		}
+		sess.sendReplay(ctx, b)
+		sessionRepository.StopSession(user.ID)
+		return
+	}
 
-			if err != nil {
-				log.Print(err.Error())
+	// picking up, dropping and giving don't use the turn, so the turn timer keeps running
+	if sess.GameSession.Turn() == turn {
+		sess.askMove(ctx, b, user)
+		return
+	}
+
+	sess.announceTurn(ctx, b)
+}
+
+// playerMaps writes the maps of the player in the message and returns them drawn as a PNG picture.
+// It must be called with s.mu locked
+func (s *MemSession) playerMaps(msg *strings.Builder, pl *lab.Player) []byte {
+	view := s.GameSession.PlayerView(pl)
+	maps := []*lab.PlayerMap{&view}
+	for i := range pl.Fragments {
+		maps = append(maps, &pl.Fragments[i])
+	}
+
+	for i, pm := range maps {
+		var markers map[lab.Position]rune
+		if i == 0 {
+			// after a river drag or a teleport the map is still the old one, the mark would give the landing cell away
+			if !s.GameSession.HasUncertainty(pl) {
+				markers = map[lab.Position]rune{pl.Pos: '@'}
 			}
+		} else {
+			msg.WriteString("\n\nfragment ")
+			msg.WriteString(strconv.Itoa(i))
 		}
+		writeTextMap(msg, &s.GameSession.World.Cells, pm, markers)
 	}
 
-	if isWin {
-		sessionRepository.StopSession(user.ID)
-		return
+	// rivers and wormholes the player or their teammates went through are marked
+	names := []string{pl.Name}
+	for _, v := range s.GameSession.Teammates(pl) {
+		names = append(names, v.Name)
+	}
+	learnt := s.GameSession.LearntCells(names...)
+	marked := func(pos lab.Position) bool {
+		_, ok := learnt[pos]
+		return ok
 	}
 
-	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID:      nextTgUser.ID,
-		Text:        "Your turn",
-		ReplyMarkup: getInGameMoveReplyKeyboard(sess.GameSession.GetCurrentPlayerPossibleActions()),
+	canvas := s.canvas(pl.Name)
+	canvas.SetMarks(marked)
+	viewImage, _ := canvas.Update(&view)
+	pictures := []goimage.Image{viewImage}
+	for i := range pl.Fragments {
+		pictures = append(pictures, image.NewPlayerMap(makeCellMapImage(false).WithMarks(marked), &pl.Fragments[i]))
+	}
+
+	f := bytes.NewBuffer(nil)
+	if err := image.Encode(f, image.JoinFragments(pictures, 16), image.PNG); err != nil {
+		log.Print(err)
+	}
+
+	return f.Bytes()
+}
+
+// sendPaths sends the whole map with paths of all players at the end of the game
+func sendPaths(ctx context.Context, b *bot.Bot, chatID int64, data []byte) {
+	_, err := b.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID: chatID,
+		Photo: &models.InputFileUpload{
+			Filename: "paths.png",
+			Data:     bytes.NewReader(data),
+		},
+		Caption: "paths of all players",
 	})
 
 	if err != nil {
@@ -524,6 +731,55 @@ func (s *InGameCommandState) Handle(ctx context.Context, b *bot.Bot, update *mod
 	}
 }
 
+// sendReplay sends everyone an animated replay of the game. It must be called with s.mu locked,
+// the replay is rendered in the background
+func (s *MemSession) sendReplay(ctx context.Context, b *bot.Bot) {
+	if s.Log == nil {
+		return
+	}
+
+	gameLog := *s.Log
+	gameLog.Record(&s.GameSession)
+	users := slices.Clone(s.Users)
+
+	tex := textures()
+	opts := replay.Options{
+		Base: func(cells *lab.CellMap) (image.Grid, error) {
+			return image.NewCellMapImage(cells, image.Options{Textures: tex, Marks: true})
+		},
+	}
+
+	go func() {
+		data := bytes.NewBuffer(nil)
+		if err := replay.WriteGIF(data, &gameLog, opts); err != nil {
+			log.Print(err.Error())
+			return
+		}
+
+		for _, x := range users {
+			_, err := b.SendAnimation(ctx, &bot.SendAnimationParams{
+				ChatID: x.ID,
+				Animation: &models.InputFileUpload{
+					Filename: "replay.gif",
+					Data:     bytes.NewReader(data.Bytes()),
+				},
+				Caption: "replay of the game",
+			})
+
+			if err != nil {
+				log.Print(err.Error())
+			}
+		}
+	}()
+}
+
+// writeTextMap writes the player map in a code block, the player is marked with @ on their current map
+func writeTextMap(msg *strings.Builder, cells *lab.CellMap, pm *lab.PlayerMap, markers map[lab.Position]rune) {
+	msg.WriteString("\n\n```\n")
+	msg.WriteString(text.String(cells, text.Options{Style: text.Unicode, View: pm, Markers: markers}))
+	msg.WriteString("```")
+}
+
 func getInGameMoveReplyKeyboard(actions []string) models.ReplyKeyboardMarkup {
 	markup := [][]models.KeyboardButton{
 		[]models.KeyboardButton{},
@@ -0,0 +1,88 @@
+package labyrinth
+
+// MinFragmentOverlap is the number of cells a fragment must have in common with the map to be merged
+const MinFragmentOverlap = 3
+
+// FindFragmentOffset looks for the way to lay the fragment over the map the way a player would: by the cells they
+// have seen, not by where the cells are in the world. An offset fits if at least MinFragmentOverlap common cells
+// look the same and none differs. It succeeds only if exactly one offset fits.
+func FindFragmentOffset(cells *CellMap, pm PlayerMap, fragment PlayerMap) (Position, bool) {
+	tried := map[Position]struct{}{}
+	found := Position{}
+	fits := 0
+
+	for a := range fragment.KnonwnCells {
+		for b := range pm.KnonwnCells {
+			offset := NewPosition(b.X-a.X, b.Y-a.Y)
+			if _, ok := tried[offset]; ok {
+				continue
+			}
+			tried[offset] = struct{}{}
+
+			if overlap, ok := fragmentOverlap(cells, pm, fragment, offset); ok && overlap >= MinFragmentOverlap {
+				found = offset
+				fits++
+			}
+		}
+	}
+
+	return found, fits == 1
+}
+
+// fragmentOverlap counts the common cells of the map and the fragment moved by the offset, it fails if any of
+// them looks different
+func fragmentOverlap(cells *CellMap, pm PlayerMap, fragment PlayerMap, offset Position) (int, bool) {
+	overlap := 0
+	for p := range fragment.KnonwnCells {
+		np := NewPosition(p.X+offset.X, p.Y+offset.Y)
+		if _, ok := pm.KnonwnCells[np]; !ok {
+			continue
+		}
+
+		if !looksSame(cells.Get(p), cells.Get(np)) {
+			return 0, false
+		}
+		overlap++
+	}
+
+	return overlap, true
+}
+
+// looksSame tells if a player can tell the cells apart: by their class and the way a river flows
+func looksSame(a, b Cell) bool {
+	if a.Class != b.Class {
+		return false
+	}
+
+	ra, ok := a.Custom.(*RiverCell)
+	if !ok {
+		return true
+	}
+	rb, ok := b.Custom.(*RiverCell)
+
+	return ok && ra.Dir == rb.Dir
+}
+
+// MergeFragments merges every fragment that can be laid over the current map. Returns true if any was merged.
+//
+// Maps keep the cells where they are in the world, so a fragment which fits only somewhere else is a coincidence:
+// the player would take it for the place they have been, but it isn't, and the fragment stays apart.
+func (p *Player) MergeFragments(cells *CellMap) bool {
+	merged := false
+
+	for i := 0; i < len(p.Fragments); {
+		offset, ok := FindFragmentOffset(cells, p.Map, p.Fragments[i])
+		if !ok || offset != (Position{}) {
+			i++
+			continue
+		}
+
+		p.Map.Merge(p.Fragments[i])
+		p.Fragments = append(p.Fragments[:i], p.Fragments[i+1:]...)
+		merged = true
+		// the grown map may now match fragments that were checked before
+		i = 0
+	}
+
+	return merged
+}
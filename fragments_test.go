@@ -0,0 +1,128 @@
+package labyrinth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPlayerMap(cells ...Position) PlayerMap {
+	pm := NewPlayerMap(cells[0])
+	for _, p := range cells {
+		pm.Learn(p)
+	}
+
+	return pm
+}
+
+// row returns cells from x1 to x2 of the row
+func row(y, x1, x2 int) []Position {
+	var res []Position
+	for x := x1; x <= x2; x++ {
+		res = append(res, NewPosition(x, y))
+	}
+
+	return res
+}
+
+func TestFindFragmentOffset(t *testing.T) {
+	w := NewWorldFromString(`
+wwwwwwwww
+w       w
+wwwwwwwww
+w   w   w
+wwwwwwwww
+`)
+
+	tests := []struct {
+		name     string
+		pm       PlayerMap
+		fragment PlayerMap
+		want     Position
+		wantOk   bool
+	}{
+		{
+			name:     "walls at both ends",
+			pm:       newTestPlayerMap(row(3, 0, 4)...),
+			fragment: newTestPlayerMap(row(3, 0, 3)...),
+			want:     NewPosition(0, 0),
+			wantOk:   true,
+		},
+		{
+			name:     "plain row fits anywhere",
+			pm:       newTestPlayerMap(row(1, 2, 6)...),
+			fragment: newTestPlayerMap(row(1, 1, 5)...),
+			wantOk:   false,
+		},
+		{
+			name:     "the map has two identical corridors",
+			pm:       newTestPlayerMap(row(3, 0, 8)...),
+			fragment: newTestPlayerMap(row(3, 4, 8)...),
+			wantOk:   false,
+		},
+		{
+			name:     "too small overlap",
+			pm:       newTestPlayerMap(row(3, 0, 1)...),
+			fragment: newTestPlayerMap(row(3, 0, 1)...),
+			wantOk:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FindFragmentOffset(&w.Cells, tt.pm, tt.fragment)
+			assert.Equal(t, tt.wantOk, ok)
+			if tt.wantOk {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPlayer_MergeFragments(t *testing.T) {
+	w := NewWorldFromString(`
+wwwwwwwww
+w   w   w
+wwwwwwwww
+`)
+
+	p := NewPlayer("alex", NewPosition(1, 1))
+	p.Map = newTestPlayerMap(row(1, 0, 2)...)
+	p.Pos = NewPosition(3, 1)
+	p.NewMap()
+	assert.Len(t, p.Fragments, 1)
+
+	p.Map.Learn(NewPosition(2, 1))
+	assert.False(t, p.MergeFragments(&w.Cells))
+
+	p.Map.Learn(NewPosition(1, 1))
+	assert.False(t, p.MergeFragments(&w.Cells), "only two cells are common")
+
+	p.Map.Learn(NewPosition(0, 1))
+	assert.True(t, p.MergeFragments(&w.Cells))
+	assert.Empty(t, p.Fragments)
+	assert.Equal(t, newTestPlayerMap(row(1, 0, 3)...).KnonwnCells, p.Map.KnonwnCells)
+}
+
+func TestPlayer_MergeFragmentsIdenticalCorridors(t *testing.T) {
+	w := NewWorldFromString(`
+wwwwwwwww
+w   w   w
+wwwwwwwww
+`)
+
+	// the player has seen the right corridor and is in the left one, which looks the same
+	p := NewPlayer("alex", NewPosition(5, 1))
+	p.Map = newTestPlayerMap(row(1, 4, 8)...)
+	p.Pos = NewPosition(1, 1)
+	p.NewMap()
+	for x := 0; x <= 4; x++ {
+		p.Map.Learn(NewPosition(x, 1))
+	}
+
+	offset, ok := FindFragmentOffset(&w.Cells, p.Map, p.Fragments[0])
+	assert.True(t, ok, "the corridors look the same")
+	assert.Equal(t, NewPosition(-4, 0), offset)
+
+	assert.False(t, p.MergeFragments(&w.Cells), "the fragment isn't where the player takes it to be")
+	assert.Len(t, p.Fragments, 1)
+}
@@ -2,21 +2,24 @@ module github.com/kepkin/labyrinth
 
 go 1.23.1
 
+require (
+	github.com/gdamore/tcell/v2 v2.7.1
+	github.com/go-telegram/bot v1.11.1
+	github.com/go-telegram/ui v0.4.1
+	github.com/goombaio/namegenerator v0.0.0-20181006234301-989e774b106e
+	github.com/hashicorp/golang-lru/v2 v2.0.7
+	github.com/rivo/tview v0.0.0-20241103174730-c76f7879f592
+	github.com/stretchr/testify v1.10.0
+)
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gdamore/encoding v1.0.0 // indirect
-	github.com/gdamore/tcell/v2 v2.7.1 // indirect
-	github.com/go-telegram/bot v1.11.1 // indirect
-	github.com/go-telegram/ui v0.4.1 // indirect
 	github.com/google/uuid v1.6.0 // indirect
-	github.com/goombaio/namegenerator v0.0.0-20181006234301-989e774b106e // indirect
-	github.com/hashicorp/golang-lru/v2 v2.0.7 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/rivo/tview v0.0.0-20241103174730-c76f7879f592 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
 	golang.org/x/sys v0.17.0 // indirect
 	golang.org/x/term v0.17.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
@@ -0,0 +1,137 @@
+package labyrinth
+
+import "slices"
+
+// Step is an action of a player as it happened. Events include monster and game over events which followed it
+type Step struct {
+	Turn   int
+	Player string
+	Action string
+	From   Position
+	To     Position
+	Events []Event
+}
+
+func (st Step) has(t EventType) bool {
+	return slices.ContainsFunc(st.Events, func(e Event) bool { return e.Type == t })
+}
+
+type SegmentKind int
+
+const (
+	WalkSegment SegmentKind = iota
+	// DragSegment is a step downstream a river
+	DragSegment
+	// JumpSegment is a teleport between wormholes
+	JumpSegment
+)
+
+// Segment is a part of a player's way between two cells
+type Segment struct {
+	From Position
+	To   Position
+	Kind SegmentKind
+}
+
+// Segments returns how the player went from From to To during the step. Actions which aren't moves have no segments
+func (st Step) Segments(w *World) []Segment {
+	dir, err := MoveDirectionFromWord(st.Action)
+	teleport := st.has(TeleportEventType)
+	if err != nil || (st.From == st.To && !teleport) {
+		return nil
+	}
+
+	via := st.From.Next(dir)
+	if via == st.To {
+		return []Segment{{From: st.From, To: st.To, Kind: WalkSegment}}
+	}
+
+	if w.Cells.Get(via).Class == CellWall {
+		// a wormhole throws the player out when they hit a wall
+		return []Segment{{From: st.From, To: st.To, Kind: JumpSegment}}
+	}
+
+	res := []Segment{{From: st.From, To: via, Kind: WalkSegment}}
+	pos := via
+	if st.has(RiverDragEventType) {
+		for range w.Cells.Rows() * w.Cells.Cols() {
+			river, ok := w.Cells.Get(pos).Custom.(*RiverCell)
+			if pos == st.To || !ok || river.Dir == MoveNil {
+				break
+			}
+			res = append(res, Segment{From: pos, To: pos.Next(river.Dir), Kind: DragSegment})
+			pos = pos.Next(river.Dir)
+		}
+	}
+
+	if pos != st.To {
+		kind := WalkSegment
+		if teleport {
+			kind = JumpSegment
+		}
+		res = append(res, Segment{From: pos, To: st.To, Kind: kind})
+	}
+
+	return res
+}
+
+// Path returns the cells the player went through during the step, starting with From and ending with To
+func (st Step) Path(w *World) []Position {
+	res := []Position{st.From}
+	for _, v := range st.Segments(w) {
+		res = append(res, v.To)
+	}
+
+	return res
+}
+
+// Paths returns cells every player went through, in order of steps
+func (s *Session) Paths() map[string][]Position {
+	res := map[string][]Position{}
+	for _, st := range s.History {
+		path := st.Path(s.World)
+		if prev := res[st.Player]; len(prev) > 0 && prev[len(prev)-1] == path[0] {
+			path = path[1:]
+		}
+		res[st.Player] = append(res[st.Player], path...)
+	}
+
+	return res
+}
+
+// LearntCells returns cells whose river flow or wormhole connection the players have learnt on their way: river
+// cells which dragged them, mouths where drags ended and wormholes they went through
+func (s *Session) LearntCells(names ...string) map[Position]struct{} {
+	res := map[Position]struct{}{}
+	for _, st := range s.History {
+		if !slices.Contains(names, st.Player) {
+			continue
+		}
+
+		for _, seg := range st.Segments(s.World) {
+			switch seg.Kind {
+			case DragSegment:
+				res[seg.From] = struct{}{}
+				if river, ok := s.World.Cells.Get(seg.To).Custom.(*RiverCell); ok && river.Dir == MoveNil {
+					res[seg.To] = struct{}{}
+				}
+			case JumpSegment:
+				res[seg.From] = struct{}{}
+				res[seg.To] = struct{}{}
+			}
+		}
+	}
+
+	return res
+}
+
+// IsPublic tells if everybody at the table hears about the event, not only the player it happened to
+func IsPublic(e Event) bool {
+	switch e.Type {
+	case MoveEventType, WinEventType, GameStartEventType, PlayerDiedEventType, TreasureOutEventType,
+		GameOverEventType, RoundEndEventType, SkipEventType, PlayerRemovedEventType, GiveObjectEventType:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,126 @@
+package labyrinth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_History(t *testing.T) {
+	w := NewWorldFromString(`
+wwwwww
+w  ↓ w
+w  ↓ w
+w  ↓ w
+wwwwww
+`)
+	s := &Session{World: w}
+	s.AddPlayer("alex", NewPosition(2, 1))
+	s.AddPlayer("tanya", NewPosition(1, 3))
+
+	s.Do("east")
+	s.Do("north")
+	s.Do("west")
+	s.Do("impossible")
+
+	assert.Len(t, s.History, 3)
+	assert.Equal(t, Step{Turn: 1, Player: "tanya", Action: "north", From: NewPosition(1, 3), To: NewPosition(1, 2), Events: s.History[1].Events}, s.History[1])
+
+	drag := s.History[0]
+	assert.Equal(t, NewPosition(2, 1), drag.From)
+	assert.Equal(t, NewPosition(3, 3), drag.To)
+	assert.Equal(t, []Position{{2, 1}, {3, 1}, {3, 2}, {3, 3}}, drag.Path(w))
+
+	tests := []struct {
+		name string
+		step Step
+		want []Position
+	}{
+		{
+			name: "walk",
+			step: s.History[1],
+			want: []Position{{1, 3}, {1, 2}},
+		},
+		{
+			name: "wall",
+			step: Step{Action: "north", From: NewPosition(1, 1), To: NewPosition(1, 1)},
+			want: []Position{{1, 1}},
+		},
+		{
+			name: "river drag",
+			step: Step{Action: "east", From: NewPosition(2, 1), To: NewPosition(3, 3), Events: []Event{NewEventf2(RiverDragEventType, "alex", "")}},
+			want: []Position{{2, 1}, {3, 1}, {3, 2}, {3, 3}},
+		},
+		{
+			name: "teleport",
+			step: Step{Action: "east", From: NewPosition(1, 1), To: NewPosition(4, 3), Events: []Event{NewEventf2(TeleportEventType, "alex", "")}},
+			want: []Position{{1, 1}, {2, 1}, {4, 3}},
+		},
+		{
+			name: "pick up",
+			step: Step{Action: "pick up key", From: NewPosition(1, 1), To: NewPosition(1, 1)},
+			want: []Position{{1, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.step.Path(w))
+		})
+	}
+}
+
+func TestSession_Paths(t *testing.T) {
+	w := NewWorldFromString(`
+wwwww
+w   w
+wwwww
+`)
+	s := &Session{World: w}
+	s.AddPlayer("alex", NewPosition(1, 1))
+
+	s.Do("east")
+	s.Do("east")
+	s.Do("east")
+
+	assert.Equal(t, map[string][]Position{"alex": {{1, 1}, {2, 1}, {3, 1}}}, s.Paths())
+}
+
+func TestStep_Segments(t *testing.T) {
+	w := NewWorldFromString(`
+wwwww
+w ↓ w
+w ↓ w
+w   w
+wwwww
+`)
+
+	drag := Step{Action: "east", From: NewPosition(1, 1), To: NewPosition(2, 3), Events: []Event{NewEventf2(RiverDragEventType, "alex", "")}}
+	assert.Equal(t, []Segment{
+		{From: NewPosition(1, 1), To: NewPosition(2, 1), Kind: WalkSegment},
+		{From: NewPosition(2, 1), To: NewPosition(2, 2), Kind: DragSegment},
+		{From: NewPosition(2, 2), To: NewPosition(2, 3), Kind: DragSegment},
+	}, drag.Segments(w))
+
+	thrown := Step{Action: "north", From: NewPosition(1, 1), To: NewPosition(3, 3), Events: []Event{NewEventf2(TeleportEventType, "alex", "")}}
+	assert.Equal(t, []Segment{{From: NewPosition(1, 1), To: NewPosition(3, 3), Kind: JumpSegment}}, thrown.Segments(w))
+}
+
+func TestSession_LearntCells(t *testing.T) {
+	w := NewWorldFromString(`
+wwwwww
+w  ↓ w
+w  ↓ w
+w  ↓ w
+wwwwww
+`)
+	s := &Session{World: w}
+	s.AddPlayer("alex", NewPosition(2, 1))
+	s.AddPlayer("tanya", NewPosition(1, 3))
+
+	s.Do("east")
+	s.Do("north")
+
+	assert.Equal(t, map[Position]struct{}{{3, 1}: {}, {3, 2}: {}}, s.LearntCells("alex"), "the river dragged alex")
+	assert.Empty(t, s.LearntCells("tanya"))
+}
@@ -0,0 +1,119 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// Canvas keeps a map drawn in memory between moves. Update redraws only the cells which changed since the
+// last update, so following a player's map turn by turn costs a few tiles instead of the whole picture.
+type Canvas struct {
+	cm  *CellMap
+	img *image.RGBA
+	// corner is the cell in the top left corner of the picture
+	corner lab.Position
+	// drawn are the tiles on the picture now
+	drawn map[lab.Position]tileKey
+}
+
+func NewCanvas(cm *CellMap) *Canvas {
+	return &Canvas{cm: cm, drawn: map[lab.Position]tileKey{}}
+}
+
+// SetMarks makes the next updates draw marks only on cells marked picks, see CellMap.WithMarks.
+// Cells whose marks change are redrawn
+func (c *Canvas) SetMarks(marked func(pos lab.Position) bool) {
+	c.cm = c.cm.WithMarks(marked)
+}
+
+// Update draws the player map, or the whole map if view is nil. It returns the picture and the part of it which
+// changed. The picture is reused by the next update, so encode or copy it before that.
+func (c *Canvas) Update(view *lab.PlayerMap) (*image.RGBA, image.Rectangle) {
+	cells := c.cm.cmap
+	corner := lab.Position{}
+	cols, rows := cells.Cols(), cells.Rows()
+	if view != nil {
+		corner = view.LeftCorner
+		cols, rows = view.Rect()
+	}
+
+	changed := image.Rectangle{}
+	if size := (image.Point{X: cols * c.cm.cellSize.X, Y: rows * c.cm.cellSize.Y}); c.img == nil || c.corner != corner || c.img.Bounds().Size() != size {
+		c.resize(corner, size)
+		changed = c.img.Bounds()
+	}
+
+	known := func(pos lab.Position) bool {
+		if pos.X < 0 || pos.Y < 0 || pos.X >= cells.Cols() || pos.Y >= cells.Rows() {
+			return false
+		}
+		if view == nil {
+			return true
+		}
+		_, ok := view.KnonwnCells[pos]
+		return ok
+	}
+
+	// the view may be another map now, forgotten cells are black again
+	for pos := range c.drawn {
+		if !known(pos) {
+			r := c.cellRect(pos)
+			draw.Draw(c.img, r, image.NewUniform(color.Black), image.Point{}, draw.Src)
+			delete(c.drawn, pos)
+			changed = changed.Union(r)
+		}
+	}
+
+	update := func(pos lab.Position) {
+		cell := cells.Get(pos)
+		marked := c.cm.hasMark(pos)
+		key := c.cm.tileKey(cell, marked)
+		if drawn, ok := c.drawn[pos]; ok && drawn == key {
+			return
+		}
+
+		r := c.cellRect(pos)
+		draw.Draw(c.img, r, c.cm.tile(cell, marked), image.Point{}, draw.Src)
+		c.drawn[pos] = key
+		changed = changed.Union(r)
+	}
+
+	if view == nil {
+		for pos := range cells.All() {
+			update(pos)
+		}
+	} else {
+		for pos := range view.KnonwnCells {
+			if known(pos) {
+				update(pos)
+			}
+		}
+	}
+
+	return c.img, changed
+}
+
+func (c *Canvas) cellRect(pos lab.Position) image.Rectangle {
+	return c.cm.CellRect(pos).Sub(c.cm.CellRect(c.corner).Min)
+}
+
+// resize makes a picture of the new size keeping the tiles which are still on it
+func (c *Canvas) resize(corner lab.Position, size image.Point) {
+	img := image.NewRGBA(image.Rectangle{Max: size})
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	if c.img != nil {
+		shift := c.cm.CellRect(c.corner).Min.Sub(c.cm.CellRect(corner).Min)
+		draw.Draw(img, c.img.Bounds().Add(shift), c.img, image.Point{}, draw.Src)
+	}
+
+	c.img, c.corner = img, corner
+	for pos := range c.drawn {
+		if !c.cellRect(pos).In(img.Bounds()) {
+			delete(c.drawn, pos)
+		}
+	}
+}
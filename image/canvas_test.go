@@ -0,0 +1,119 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+	md "github.com/kepkin/labyrinth/markdown"
+)
+
+func TestCanvas_Update(t *testing.T) {
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, _, err := wb.Build(renderMap)
+	assert.NoError(t, err)
+
+	cm, err := NewCellMapImage(&w.Cells, Options{Items: true})
+	assert.NoError(t, err)
+	size := cm.cellSize.X
+
+	c := NewCanvas(cm)
+	img, changed := c.Update(nil)
+	assert.True(t, bytes.Equal(Render(cm).Pix, img.Pix))
+	assert.Equal(t, img.Bounds(), changed)
+
+	_, changed = c.Update(nil)
+	assert.True(t, changed.Empty(), "nothing changed")
+
+	w.Cells.Get(lab.NewPosition(1, 2)).Items = nil
+	img, changed = c.Update(nil)
+	assert.Equal(t, image.Rect(size, 2*size, 2*size, 3*size), changed, "only the cell where items were picked up")
+	assert.True(t, bytes.Equal(Render(cm).Pix, img.Pix))
+}
+
+func TestCanvas_UpdateView(t *testing.T) {
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, _, err := wb.Build(renderMap)
+	assert.NoError(t, err)
+
+	cm, err := NewCellMapImage(&w.Cells, Options{})
+	assert.NoError(t, err)
+	size := cm.cellSize.X
+
+	view := lab.NewPlayerMap(lab.NewPosition(2, 2))
+	view.Learn(lab.NewPosition(3, 2))
+	view.Learn(lab.NewPosition(3, 1))
+
+	c := NewCanvas(cm)
+	check := func(view *lab.PlayerMap) image.Rectangle {
+		img, changed := c.Update(view)
+		want := NewPlayerMap(cm, view).Render()
+		assert.Equal(t, want.Bounds(), img.Bounds())
+		assert.True(t, bytes.Equal(want.Pix, img.Pix), "the canvas differs from the player map")
+		return changed
+	}
+
+	assert.Equal(t, image.Rect(0, 0, 2*size, 2*size), check(&view))
+
+	view.Learn(lab.NewPosition(2, 1))
+	assert.Equal(t, image.Rect(0, 0, size, size), check(&view), "a cell inside the known rect")
+
+	view.Learn(lab.NewPosition(1, 1))
+	assert.Equal(t, image.Rect(0, 0, 3*size, 2*size), check(&view), "the map grew to the west")
+
+	other := lab.NewPlayerMap(lab.NewPosition(3, 2))
+	other.Learn(lab.NewPosition(4, 2))
+	check(&other)
+
+	check(&view)
+}
+
+// walk is a player learning the 30x30 map cell by cell
+type walk struct {
+	cells *lab.CellMap
+	view  lab.PlayerMap
+	step  int
+}
+
+func (w *walk) next() *lab.PlayerMap {
+	inner := (w.cells.Cols() - 2) * (w.cells.Rows() - 2)
+	if w.step%inner == 0 {
+		w.view = lab.NewPlayerMap(lab.NewPosition(1, 1))
+	}
+
+	w.view.Learn(lab.NewPosition(1+w.step%inner%(w.cells.Cols()-2), 1+w.step%inner/(w.cells.Cols()-2)))
+	w.step++
+
+	return &w.view
+}
+
+func BenchmarkPlayerMap_PixelByPixel(b *testing.B) {
+	cm := benchmarkCellMap(b)
+	wk := &walk{cells: cm.cmap}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		slowRender(NewPlayerMap(cm, wk.next()))
+	}
+}
+
+func BenchmarkPlayerMap_Render(b *testing.B) {
+	cm := benchmarkCellMap(b)
+	wk := &walk{cells: cm.cmap}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewPlayerMap(cm, wk.next()).Render()
+	}
+}
+
+func BenchmarkCanvas_Update(b *testing.B) {
+	cm := benchmarkCellMap(b)
+	wk := &walk{cells: cm.cmap}
+	c := NewCanvas(cm)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Update(wk.next())
+	}
+}
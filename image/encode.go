@@ -0,0 +1,221 @@
+package image
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"slices"
+)
+
+// Format is how pictures are written
+type Format string
+
+const (
+	// PNG keeps every colour, textures stay sharp
+	PNG Format = "png"
+	// PalettedPNG has up to 256 colours picked for the picture, files are several times smaller
+	PalettedPNG Format = "png8"
+	GIF         Format = "gif"
+	JPEG        Format = "jpeg"
+)
+
+var Formats = []Format{PNG, PalettedPNG, GIF, JPEG}
+
+func ParseFormat(name string) (Format, error) {
+	if f := Format(name); slices.Contains(Formats, f) {
+		return f, nil
+	}
+	if name == "jpg" {
+		return JPEG, nil
+	}
+
+	return "", fmt.Errorf("unknown image format %v, use one of %v", name, Formats)
+}
+
+// Ext is the file extension of the format
+func (f Format) Ext() string {
+	switch f {
+	case PalettedPNG:
+		return ".png"
+	case JPEG:
+		return ".jpg"
+	}
+
+	return "." + string(f)
+}
+
+// Encode writes the picture. It's rendered at once, so maps of this package are fast to write
+func Encode(w io.Writer, img image.Image, f Format) error {
+	switch f {
+	case PNG:
+		return png.Encode(w, Render(img))
+	case PalettedPNG:
+		return png.Encode(w, Paletted(Render(img)))
+	case GIF:
+		return gif.Encode(w, Paletted(Render(img)), nil)
+	case JPEG:
+		return jpeg.Encode(w, Render(img), nil)
+	}
+
+	return fmt.Errorf("unknown image format %v", f)
+}
+
+// colour channels are cut to 5 bits, so the histogram and the table of nearest colours are small arrays
+const (
+	channelBits = 5
+	buckets     = 1 << (3 * channelBits)
+)
+
+func bucket(r, g, b uint8) int {
+	return int(r>>(8-channelBits))<<(2*channelBits) | int(g>>(8-channelBits))<<channelBits | int(b>>(8-channelBits))
+}
+
+// Paletted converts the picture to 256 colours chosen with the median cut: the colours of the picture are split
+// in two by the widest channel, again and again, until there are 256 groups; the average of each group is in the
+// palette. Pictures with fewer colours keep them exactly.
+func Paletted(img *image.RGBA) *image.Paletted {
+	b := img.Bounds()
+
+	exact := map[color.RGBA]uint8{}
+	var palette color.Palette
+	for y := b.Min.Y; y < b.Max.Y && exact != nil; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			if _, ok := exact[c]; ok {
+				continue
+			}
+			if len(palette) == 256 {
+				exact = nil
+				break
+			}
+			exact[c] = uint8(len(palette))
+			palette = append(palette, c)
+		}
+	}
+
+	if exact != nil {
+		res := image.NewPaletted(b, palette)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				res.Pix[res.PixOffset(x, y)] = exact[img.RGBAAt(x, y)]
+			}
+		}
+		return res
+	}
+
+	var histogram [buckets]int
+	var sums [buckets][3]int
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := img.PixOffset(b.Min.X, y)
+		for x := b.Min.X; x < b.Max.X; x, i = x+1, i+4 {
+			r, g, bl := img.Pix[i], img.Pix[i+1], img.Pix[i+2]
+			k := bucket(r, g, bl)
+			histogram[k]++
+			sums[k][0] += int(r)
+			sums[k][1] += int(g)
+			sums[k][2] += int(bl)
+		}
+	}
+
+	palette, table := medianCut(&histogram, &sums, 256)
+
+	res := image.NewPaletted(b, palette)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := img.PixOffset(b.Min.X, y)
+		o := res.PixOffset(b.Min.X, y)
+		for x := b.Min.X; x < b.Max.X; x, i, o = x+1, i+4, o+1 {
+			res.Pix[o] = table[bucket(img.Pix[i], img.Pix[i+1], img.Pix[i+2])]
+		}
+	}
+
+	return res
+}
+
+// box is a group of colour buckets
+type box struct {
+	buckets []int
+	pixels  int
+}
+
+func channel(k int, c int) int {
+	return k >> ((2 - c) * channelBits) & (1<<channelBits - 1)
+}
+
+// widest returns the channel with the largest range of values
+func (bx box) widest() int {
+	best, bestRange := 0, -1
+	for c := 0; c < 3; c++ {
+		lo, hi := 1<<channelBits, -1
+		for _, k := range bx.buckets {
+			lo, hi = min(lo, channel(k, c)), max(hi, channel(k, c))
+		}
+		if hi-lo > bestRange {
+			best, bestRange = c, hi-lo
+		}
+	}
+
+	return best
+}
+
+func medianCut(histogram *[buckets]int, sums *[buckets][3]int, colors int) (color.Palette, *[buckets]uint8) {
+	all := box{}
+	for k, n := range histogram {
+		if n > 0 {
+			all.buckets = append(all.buckets, k)
+			all.pixels += n
+		}
+	}
+
+	boxes := []box{all}
+	for len(boxes) < colors {
+		// the box with most pixels among the ones which can be split
+		idx := -1
+		for i, bx := range boxes {
+			if len(bx.buckets) > 1 && (idx < 0 || bx.pixels > boxes[idx].pixels) {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			break
+		}
+
+		bx := boxes[idx]
+		c := bx.widest()
+		slices.SortFunc(bx.buckets, func(a, b int) int { return channel(a, c) - channel(b, c) })
+
+		half, split := 0, 1
+		for i, k := range bx.buckets[:len(bx.buckets)-1] {
+			half += histogram[k]
+			split = i + 1
+			if half*2 >= bx.pixels {
+				break
+			}
+		}
+
+		left := box{buckets: bx.buckets[:split], pixels: half}
+		right := box{buckets: bx.buckets[split:], pixels: bx.pixels - half}
+		boxes[idx] = left
+		boxes = append(boxes, right)
+	}
+
+	palette := make(color.Palette, 0, len(boxes))
+	table := &[buckets]uint8{}
+	for i, bx := range boxes {
+		var sum [3]int
+		for _, k := range bx.buckets {
+			for c := range sum {
+				sum[c] += sums[k][c]
+			}
+			table[k] = uint8(i)
+		}
+
+		n := max(1, bx.pixels)
+		palette = append(palette, color.RGBA{R: uint8(sum[0] / n), G: uint8(sum[1] / n), B: uint8(sum[2] / n), A: 255})
+	}
+
+	return palette, table
+}
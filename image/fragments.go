@@ -0,0 +1,68 @@
+package image
+
+import (
+	"image"
+	"image/color"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// Fragments draws several player maps side by side, separated by a black gap
+type Fragments struct {
+	maps []image.Image
+	// left edge of every map
+	offsets []int
+	width   int
+	height  int
+}
+
+func NewFragments(cmap *CellMap, playerMaps []*lab.PlayerMap, gap int) *Fragments {
+	maps := make([]image.Image, len(playerMaps))
+	for i, pm := range playerMaps {
+		maps[i] = NewPlayerMap(cmap, pm)
+	}
+
+	return JoinFragments(maps, gap)
+}
+
+// JoinFragments puts maps which are already drawn side by side, e.g. pictures of a Canvas
+func JoinFragments(maps []image.Image, gap int) *Fragments {
+	res := &Fragments{}
+	for i, m := range maps {
+		if i > 0 {
+			res.width += gap
+		}
+
+		res.maps = append(res.maps, m)
+		res.offsets = append(res.offsets, res.width)
+
+		b := m.Bounds()
+		res.width += b.Dx()
+		res.height = max(res.height, b.Dy())
+	}
+
+	return res
+}
+
+func (f *Fragments) Bounds() image.Rectangle {
+	return image.Rect(0, 0, f.width, f.height)
+}
+
+func (Fragments) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (f *Fragments) At(x, y int) color.Color {
+	for i := len(f.maps) - 1; i >= 0; i-- {
+		if x < f.offsets[i] {
+			continue
+		}
+
+		if (image.Point{X: x - f.offsets[i], Y: y}).In(f.maps[i].Bounds()) {
+			return f.maps[i].At(x-f.offsets[i], y)
+		}
+		break
+	}
+
+	return color.Black
+}
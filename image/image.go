@@ -1,10 +1,9 @@
 package image
 
 import (
+	"fmt"
 	"image"
 	"image/color"
-	"image/jpeg"
-	"os"
 
 	lab "github.com/kepkin/labyrinth"
 )
@@ -74,10 +73,38 @@ func (BlackImage) At(x, y int) color.Color {
 	return color.Black
 }
 
+type Options struct {
+	// Textures draw the cells, DefaultTextures are used if it's nil
+	Textures *Textures
+	// Items draws items lying in cells. Players don't see them, so it's for the master's map
+	Items bool
+	// Marks draws arrows where rivers flow, frames river mouths and labels wormholes with their system and
+	// index on every cell. Leave them off for maps players see, they don't know where a wormhole leads or where
+	// a river ends, and use CellMap.WithMarks to mark only what a player has learnt
+	Marks bool
+}
+
 type CellMap struct {
 	cmap     *lab.CellMap
 	cellSize image.Point
-	textures map[string]image.Image
+	textures *Textures
+	items    bool
+	marks    marks
+	// marked picks the cells which have their marks drawn, all cells have them if it's nil
+	marked func(pos lab.Position) bool
+	tiles  *tileCache
+}
+
+// WithMarks returns the map drawing marks only on cells marked picks, for example cells a player has learnt
+// with lab.Session.LearntCells. The maps share textures and drawn tiles
+func (cm *CellMap) WithMarks(marked func(pos lab.Position) bool) *CellMap {
+	res := *cm
+	res.marked = marked
+	return &res
+}
+
+func (cm *CellMap) hasMark(pos lab.Position) bool {
+	return cm.marked == nil || cm.marked(pos)
 }
 
 func (cm *CellMap) Bounds() image.Rectangle {
@@ -94,7 +121,7 @@ func (cm *CellMap) Bounds() image.Rectangle {
 }
 
 func (cm *CellMap) ColorModel() color.Model {
-	return cm.textures[lab.CellWall].ColorModel()
+	return color.RGBAModel
 }
 
 func (cm *CellMap) colorToCellMapPos(x, y int) (lab.Position, int, int) {
@@ -105,58 +132,103 @@ func (cm *CellMap) colorToCellMapPos(x, y int) (lab.Position, int, int) {
 	return p, xx, yy
 }
 
+// textureName is the texture of the cell, cells without their own texture look like walls
+func (cm *CellMap) textureName(cell lab.Cell) string {
+	if cell.Class == lab.CellRiver {
+		if river, ok := cell.Custom.(*lab.RiverCell); ok && river.Dir == lab.MoveNil {
+			return TextureMouth
+		}
+	}
+
+	if _, ok := cm.textures.Get(cell.Class); ok {
+		return cell.Class
+	}
+
+	return lab.CellWall
+}
+
+func itemTextureName(item *lab.Item) string {
+	switch item.ID {
+	case lab.Treasure:
+		return TextureTreasure
+	case lab.FakeTreasure:
+		return TextureFakeTreasure
+	}
+
+	return TextureKey
+}
+
 func (cm *CellMap) At(x, y int) color.Color {
 	p, xx, yy := cm.colorToCellMapPos(x, y)
 
-	cell := cm.cmap.Get(p)
+	return cm.pixel(cm.cmap.Get(p), cm.hasMark(p), xx, yy)
+}
 
-	texture, ok := cm.textures[cell.Class]
-	if !ok {
-		texture = cm.textures[lab.CellWall]
+// pixel is the colour of the cell at x, y inside the cell
+func (cm *CellMap) pixel(cell lab.Cell, marked bool, xx, yy int) color.RGBA {
+	texture, _ := cm.textures.Get(cm.textureName(cell))
+	res := texture.RGBAAt(xx, yy)
+	if marked {
+		if mark, ok := cm.marks[cellMarkKey(cell)]; ok {
+			res = over(mark.RGBAAt(xx, yy), res)
+		}
 	}
-	return texture.At(xx, yy)
+	if cm.items {
+		res = cm.itemAt(cell, xx, yy, res)
+	}
+
+	return res
 }
 
-func NewCellMapImage(cmap *lab.CellMap) (*CellMap, error) {
-	textureSize := 0
+// itemAt draws items in a row from the bottom left corner of the cell over the texture
+func (cm *CellMap) itemAt(cell lab.Cell, x, y int, under color.RGBA) color.RGBA {
+	side := cm.textures.Size / 3
+	top := cm.cellSize.Y - side
+	if len(cell.Items) == 0 || y < top || side == 0 {
+		return under
+	}
 
-	res := &CellMap{
-		cmap:     cmap,
-		textures: map[string]image.Image{},
+	i := x / side
+	if i >= len(cell.Items) {
+		return under
 	}
 
-	fileMap := map[string]string{
-		lab.CellEarth:    "./image/Grass_01.jpg",
-		lab.CellRiver:    "./image/water.jpg",
-		lab.CellWall:     "./image/stone2.jpg",
-		lab.CellWormHole: "./image/Grass_portal.jpg",
+	sprite, ok := cm.textures.Get(itemTextureName(cell.Items[i]))
+	if !ok {
+		return under
 	}
 
-	for key, path := range fileMap {
-		var err error
-		f, err := os.Open(path)
+	return over(sprite.RGBAAt(x-i*side, y-top), under)
+}
 
+// NewCellMapImage draws the map with textures
+func NewCellMapImage(cmap *lab.CellMap, opts Options) (*CellMap, error) {
+	textures := opts.Textures
+	if textures == nil {
+		var err error
+		textures, err = DefaultTextures()
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		res.textures[key], err = jpeg.Decode(f)
-		if textureSize == 0 {
-			textureSize = res.textures[key].Bounds().Dx()
-		} else if textureSize != res.textures[key].Bounds().Dx() {
-			panic("texture has different sizes")
-		}
-
-		if err != nil {
-			return nil, err
+	for _, name := range []string{lab.CellWall, TextureMouth} {
+		if _, ok := textures.Get(name); !ok {
+			return nil, fmt.Errorf("there is no %v texture", name)
 		}
-
 	}
 
-	res.textures[lab.CellExit] = res.textures[lab.CellEarth]
-	res.textures["unknown"] = &BlackImage{Width: textureSize, Height: textureSize}
-
-	res.cellSize = image.Point{textureSize, textureSize}
+	res := &CellMap{
+		cmap:     cmap,
+		cellSize: image.Point{X: textures.Size, Y: textures.Size},
+		textures: textures,
+		items:    opts.Items,
+		marks:    newMarks(cmap, textures.Size),
+		tiles:    newTileCache(),
+	}
+	if !opts.Marks {
+		res.marked = func(lab.Position) bool { return false }
+	}
 
 	return res, nil
 }
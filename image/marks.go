@@ -0,0 +1,203 @@
+package image
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+var (
+	markColor    = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	outlineColor = color.RGBA{R: 20, G: 30, B: 50, A: 255}
+)
+
+// marks are drawn over textures to show what textures can't: where rivers flow, where they end and which
+// wormholes are connected. They are drawn once for every kind of cell of the map
+type marks map[markKey]*image.RGBA
+
+// markKey tells which mark a cell has, rivers differ in direction and wormholes in system and index.
+// Cells without marks have the zero key
+type markKey struct {
+	class string
+	dir   lab.MoveDirection
+	name  string
+	idx   int
+}
+
+func cellMarkKey(cell lab.Cell) markKey {
+	switch v := cell.Custom.(type) {
+	case *lab.RiverCell:
+		return markKey{class: lab.CellRiver, dir: v.Dir}
+	case *lab.WormholeCell:
+		return markKey{class: lab.CellWormHole, name: v.Name, idx: v.Idx}
+	}
+
+	return markKey{}
+}
+
+func wormholeLabel(hole *lab.WormholeCell) string {
+	return fmt.Sprintf("%v:%v", hole.Name, hole.Idx)
+}
+
+func newMarks(cmap *lab.CellMap, size int) marks {
+	res := marks{}
+	for _, cell := range cmap.All() {
+		key := cellMarkKey(cell)
+		if _, ok := res[key]; ok || key == (markKey{}) {
+			continue
+		}
+
+		switch v := cell.Custom.(type) {
+		case *lab.RiverCell:
+			if v.Dir == lab.MoveNil {
+				res[key] = mouthMark(size)
+			} else {
+				res[key] = arrowMark(size, v.Dir)
+			}
+		case *lab.WormholeCell:
+			res[key] = labelMark(size, wormholeLabel(v))
+		}
+	}
+
+	return res
+}
+
+// fillShape paints the part of the tile inside the shape. The shape gets coordinates relative to the centre
+// of the tile, which is 1 wide. Pixels are sampled several times, so edges are smooth
+func fillShape(img *image.RGBA, inside func(x, y float64) bool, c color.RGBA, opacity float64) {
+	const samples = 4
+	size := float64(img.Bounds().Dx())
+	for py := 0; py < img.Bounds().Dy(); py++ {
+		for px := 0; px < img.Bounds().Dx(); px++ {
+			hits := 0
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					x := (float64(px)+(float64(sx)+0.5)/samples)/size - 0.5
+					y := (float64(py)+(float64(sy)+0.5)/samples)/size - 0.5
+					if inside(x, y) {
+						hits++
+					}
+				}
+			}
+			if hits == 0 {
+				continue
+			}
+
+			alpha := opacity * float64(hits) / samples / samples
+			premultiplied := color.RGBA{
+				R: uint8(float64(c.R)*alpha + 0.5),
+				G: uint8(float64(c.G)*alpha + 0.5),
+				B: uint8(float64(c.B)*alpha + 0.5),
+				A: uint8(255*alpha + 0.5),
+			}
+			img.SetRGBA(px, py, over(premultiplied, img.RGBAAt(px, py)))
+		}
+	}
+}
+
+// over draws a colour with premultiplied alpha over another one
+func over(top color.RGBA, under color.RGBA) color.RGBA {
+	a := 255 - uint32(top.A)
+	return color.RGBA{
+		R: uint8(uint32(top.R) + uint32(under.R)*a/255),
+		G: uint8(uint32(top.G) + uint32(under.G)*a/255),
+		B: uint8(uint32(top.B) + uint32(under.B)*a/255),
+		A: uint8(uint32(top.A) + uint32(under.A)*a/255),
+	}
+}
+
+// arrowMark points where the river flows
+func arrowMark(size int, dir lab.MoveDirection) *image.RGBA {
+	dx, dy := 1.0, 0.0
+	switch dir {
+	case lab.South:
+		dx, dy = 0, 1
+	case lab.West:
+		dx, dy = -1, 0
+	case lab.North:
+		dx, dy = 0, -1
+	}
+
+	// u goes along the flow, v across it
+	arrow := func(grow float64) func(x, y float64) bool {
+		return func(x, y float64) bool {
+			u, v := x*dx+y*dy, -x*dy+y*dx
+			shaft := u >= -0.3-grow && u <= 0.1 && math.Abs(v) <= 0.06+grow
+			head := u >= 0.04-grow && u <= 0.32+grow && math.Abs(v) <= 0.2*(0.32+grow-u)/0.28
+			return shaft || head
+		}
+	}
+
+	res := image.NewRGBA(image.Rect(0, 0, size, size))
+	fillShape(res, arrow(0.03), outlineColor, 0.5)
+	fillShape(res, arrow(0), markColor, 0.9)
+
+	return res
+}
+
+// mouthMark frames the tile, the river ends here
+func mouthMark(size int) *image.RGBA {
+	frame := func(width float64) func(x, y float64) bool {
+		return func(x, y float64) bool {
+			d := 0.5 - max(math.Abs(x), math.Abs(y))
+			return d >= 0.04 && d <= 0.04+width
+		}
+	}
+
+	res := image.NewRGBA(image.Rect(0, 0, size, size))
+	fillShape(res, frame(0.08), outlineColor, 0.5)
+	fillShape(res, frame(0.05), markColor, 0.9)
+
+	return res
+}
+
+// labelMark writes the system and the index of a wormhole at the bottom of the tile
+func labelMark(size int, text string) *image.RGBA {
+	scale := max(1, size/30)
+	mask := textMask(text, scale)
+	for scale > 1 && len(mask[0]) > size-4 {
+		scale--
+		mask = textMask(text, scale)
+	}
+
+	res := image.NewRGBA(image.Rect(0, 0, size, size))
+	width, height := len(mask[0]), len(mask)
+	left := (size - width) / 2
+	top := size - height - max(2, size/12)
+	border := max(1, scale/2+1)
+
+	set := func(x, y int) bool {
+		return y >= 0 && y < height && x >= 0 && x < width && mask[y][x]
+	}
+	outlined := func(x, y int) bool {
+		for oy := -border; oy <= border; oy++ {
+			for ox := -border; ox <= border; ox++ {
+				if set(x+ox, y+oy) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for y := -border; y < height+border; y++ {
+		for x := -border; x < width+border; x++ {
+			p := image.Point{X: left + x, Y: top + y}
+			if !p.In(res.Bounds()) {
+				continue
+			}
+
+			switch {
+			case set(x, y):
+				res.SetRGBA(p.X, p.Y, markColor)
+			case outlined(x, y):
+				res.SetRGBA(p.X, p.Y, outlineColor)
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,100 @@
+package image
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+	md "github.com/kepkin/labyrinth/markdown"
+)
+
+func TestTextMask(t *testing.T) {
+	mask := textMask("a:1", 2)
+	assert.Len(t, mask, 14)
+	assert.Len(t, mask[0], (3*6-1)*2)
+	assert.False(t, mask[0][0])
+	assert.True(t, mask[0][2], "top of A")
+	assert.True(t, mask[2][16], "colon")
+
+	assert.Equal(t, textMask("A", 1), textMask("a", 1))
+	assert.Equal(t, textMask("?", 1), textMask("Ж", 1))
+}
+
+func TestArrowMark(t *testing.T) {
+	east, west := arrowMark(30, lab.East), arrowMark(30, lab.West)
+	south := arrowMark(30, lab.South)
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			// edges may differ by a sample because of rounding
+			assert.InDelta(t, east.RGBAAt(x, y).A, west.RGBAAt(29-x, y).A, 20)
+			assert.InDelta(t, east.RGBAAt(x, y).A, south.RGBAAt(y, x).A, 20)
+		}
+	}
+
+	assert.Equal(t, uint8(0), east.RGBAAt(0, 0).A, "corners are transparent")
+	assert.Greater(t, east.RGBAAt(15, 15).A, uint8(200))
+	assert.Greater(t, east.RGBAAt(17, 10).A, uint8(200), "the head is wide")
+	assert.Equal(t, uint8(0), east.RGBAAt(8, 11).A, "the tail is narrow")
+}
+
+func TestNewCellMapImage_Marks(t *testing.T) {
+	dir := t.TempDir()
+	earth, river, mouth := color.RGBA{G: 255, A: 255}, color.RGBA{B: 255, A: 255}, color.RGBA{B: 100, A: 255}
+	writePNG(t, filepath.Join(dir, "earth.png"), 30, 30, earth)
+	writePNG(t, filepath.Join(dir, "river.png"), 30, 30, river)
+	writePNG(t, filepath.Join(dir, "mouth.png"), 30, 30, mouth)
+	writePNG(t, filepath.Join(dir, "wormhole.png"), 30, 30, earth)
+	textures, err := LoadTextures(dir)
+	assert.NoError(t, err)
+
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, _, err := wb.Build(`| X | 1 | 2  | 3     | 4     |
+|---|---|----|-------|-------|
+| 1 | R | RM | W:A:0 | W:A:1 |
+`)
+	assert.NoError(t, err)
+
+	at := func(img *CellMap, pos lab.Position, dx, dy int) color.Color {
+		return img.At(pos.X*30+dx, pos.Y*30+dy)
+	}
+	riverPos, mouthPos := lab.NewPosition(1, 1), lab.NewPosition(2, 1)
+	hole0, hole1 := lab.NewPosition(3, 1), lab.NewPosition(4, 1)
+
+	player, err := NewCellMapImage(&w.Cells, Options{Textures: textures})
+	assert.NoError(t, err)
+	assert.Equal(t, river, at(player, riverPos, 15, 15))
+	assert.Equal(t, mouth, at(player, mouthPos, 2, 2))
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			assert.Equal(t, at(player, hole0, x, y), at(player, hole1, x, y), "players can't tell wormholes apart")
+		}
+	}
+
+	master, err := NewCellMapImage(&w.Cells, Options{Textures: textures, Marks: true})
+	assert.NoError(t, err)
+	assert.NotEqual(t, river, at(master, riverPos, 15, 15), "arrow")
+	assert.Equal(t, river, at(master, riverPos, 15, 2))
+	assert.NotEqual(t, mouth, at(master, mouthPos, 2, 2), "frame")
+	assert.Equal(t, mouth, at(master, mouthPos, 15, 15))
+	assert.Equal(t, earth, at(master, hole0, 15, 5), "labels are at the bottom")
+
+	differs := false
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			differs = differs || at(master, hole0, x, y) != at(master, hole1, x, y)
+		}
+	}
+	assert.True(t, differs, "A:0 and A:1 have different labels")
+
+	learnt := player.WithMarks(func(pos lab.Position) bool { return pos == riverPos })
+	assert.NotEqual(t, river, at(learnt, riverPos, 15, 15), "the player knows where the river flows")
+	assert.Equal(t, mouth, at(learnt, mouthPos, 2, 2))
+	assert.Equal(t, river, at(player, riverPos, 15, 15), "the map it was made of has no marks")
+
+	canvas, _ := NewCanvas(learnt).Update(nil)
+	assert.NotEqual(t, river, canvas.At(riverPos.X*30+15, riverPos.Y*30+15))
+	assert.Equal(t, mouth, canvas.At(mouthPos.X*30+2, mouthPos.Y*30+2))
+}
@@ -0,0 +1,134 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"sync"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// Renderer is an image which can draw itself at once, much faster than pixel by pixel with At
+type Renderer interface {
+	image.Image
+	Render() *image.RGBA
+}
+
+// Render draws the image into memory. Images of this package are drawn tile by tile, others with At
+func Render(img image.Image) *image.RGBA {
+	if r, ok := img.(Renderer); ok {
+		return r.Render()
+	}
+
+	res := image.NewRGBA(img.Bounds())
+	draw.Draw(res, res.Bounds(), img, img.Bounds().Min, draw.Src)
+	return res
+}
+
+// tileKey tells tiles apart: cells of the same class with the same mark and items look the same
+type tileKey struct {
+	texture string
+	mark    markKey
+	items   string
+}
+
+// tileCache keeps tiles which have been drawn, maps have only a few kinds of cells
+type tileCache struct {
+	mu    sync.Mutex
+	tiles map[tileKey]*image.RGBA
+}
+
+func newTileCache() *tileCache {
+	return &tileCache{tiles: map[tileKey]*image.RGBA{}}
+}
+
+func (cm *CellMap) tileKey(cell lab.Cell, marked bool) tileKey {
+	res := tileKey{texture: cm.textureName(cell)}
+	if marked {
+		res.mark = cellMarkKey(cell)
+	}
+	if cm.items && len(cell.Items) > 0 {
+		names := make([]string, len(cell.Items))
+		for i, item := range cell.Items {
+			names[i] = itemTextureName(item)
+		}
+		res.items = strings.Join(names, ",")
+	}
+
+	return res
+}
+
+// tile is the picture of the cell, with its mark if marked
+func (cm *CellMap) tile(cell lab.Cell, marked bool) *image.RGBA {
+	key := cm.tileKey(cell, marked)
+
+	cm.tiles.mu.Lock()
+	defer cm.tiles.mu.Unlock()
+
+	if res, ok := cm.tiles.tiles[key]; ok {
+		return res
+	}
+
+	res := image.NewRGBA(image.Rectangle{Max: cm.cellSize})
+	for y := 0; y < cm.cellSize.Y; y++ {
+		for x := 0; x < cm.cellSize.X; x++ {
+			res.SetRGBA(x, y, cm.pixel(cell, marked, x, y))
+		}
+	}
+	cm.tiles.tiles[key] = res
+
+	return res
+}
+
+func (cm *CellMap) Render() *image.RGBA {
+	res := image.NewRGBA(cm.Bounds())
+	for pos, cell := range cm.cmap.All() {
+		draw.Draw(res, cm.CellRect(pos), cm.tile(cell, cm.hasMark(pos)), image.Point{}, draw.Src)
+	}
+
+	return res
+}
+
+func (pm *PlayerMap) Render() *image.RGBA {
+	res := image.NewRGBA(pm.Bounds())
+	draw.Draw(res, res.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	cols, rows := pm.cmap.cmap.Cols(), pm.cmap.cmap.Rows()
+	for pos := range pm.pmap.KnonwnCells {
+		if pos.X < 0 || pos.Y < 0 || pos.X >= cols || pos.Y >= rows {
+			continue
+		}
+
+		r := pm.CellRect(pos)
+		if !r.In(res.Bounds()) {
+			continue
+		}
+		draw.Draw(res, r, pm.cmap.tile(pm.cmap.cmap.Get(pos), pm.cmap.hasMark(pos)), image.Point{}, draw.Src)
+	}
+
+	return res
+}
+
+func (f *Fragments) Render() *image.RGBA {
+	res := image.NewRGBA(f.Bounds())
+	draw.Draw(res, res.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	for i, m := range f.maps {
+		// pictures of a Canvas are drawn as they are
+		if r, ok := m.(Renderer); ok {
+			m = r.Render()
+		}
+		draw.Draw(res, m.Bounds().Add(image.Point{X: f.offsets[i]}), m, m.Bounds().Min, draw.Src)
+	}
+
+	return res
+}
+
+func (t *Trails) Render() *image.RGBA {
+	res := Render(t.base)
+	draw.Draw(res, res.Bounds(), t.layer, res.Bounds().Min, draw.Over)
+
+	return res
+}
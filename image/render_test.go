@@ -0,0 +1,212 @@
+package image
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+	md "github.com/kepkin/labyrinth/markdown"
+)
+
+const renderMap = `| X | 1 | 2  | 3     | 4     |
+|---|---|----|-------|-------|
+| 1 | R | RM | W:A:0 |       |
+| 2 |   | w  |       | W:A:1 |
+
+exit: 5:2
+treasure: 1:2
+key:red: 1:2
+alex: 4:1
+`
+
+// slowRender draws the image pixel by pixel
+func slowRender(img image.Image) *image.RGBA {
+	res := image.NewRGBA(img.Bounds())
+	draw.Draw(res, res.Bounds(), img, img.Bounds().Min, draw.Src)
+	return res
+}
+
+func TestRender(t *testing.T) {
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, pls, err := wb.Build(renderMap)
+	assert.NoError(t, err)
+
+	cm, err := NewCellMapImage(&w.Cells, Options{Items: true, Marks: true})
+	assert.NoError(t, err)
+
+	known := lab.NewPlayerMap(lab.NewPosition(2, 1))
+	known.Learn(lab.NewPosition(3, 1))
+	known.Learn(lab.NewPosition(3, 2))
+	other := lab.NewPlayerMap(lab.NewPosition(1, 2))
+
+	s := &lab.Session{World: w, Players: pls}
+	s.Do("south")
+
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"cell map", cm},
+		{"player map", NewPlayerMap(cm, &known)},
+		{"fragments", NewFragments(cm, []*lab.PlayerMap{&known, &other}, 7)},
+		{"trails", NewTrails(cm, s)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := slowRender(tt.img)
+			got := Render(tt.img)
+			assert.Equal(t, want.Bounds(), got.Bounds())
+			assert.True(t, bytes.Equal(want.Pix, got.Pix), "rendered tiles differ from pixels")
+		})
+	}
+}
+
+func TestPaletted(t *testing.T) {
+	few := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			few.SetRGBA(x, y, color.RGBA{R: uint8(x * 50), G: uint8(y * 50), A: 255})
+		}
+	}
+	p := Paletted(few)
+	assert.Len(t, p.Palette, 16)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			assert.Equal(t, few.RGBAAt(x, y), p.At(x, y), "few colours are kept exactly")
+		}
+	}
+
+	many := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			many.SetRGBA(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 4), B: uint8((x + y) * 2), A: 255})
+		}
+	}
+	p = Paletted(many)
+	assert.LessOrEqual(t, len(p.Palette), 256)
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			want := many.RGBAAt(x, y)
+			got := p.At(x, y).(color.RGBA)
+			assert.InDelta(t, want.R, got.R, 24)
+			assert.InDelta(t, want.G, got.G, 24)
+			assert.InDelta(t, want.B, got.B, 24)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	w := lab.NewWorldFromString(`
+wwww
+w  w
+wwww
+`)
+	cm, err := NewCellMapImage(&w.Cells, Options{})
+	assert.NoError(t, err)
+
+	for _, f := range Formats {
+		t.Run(string(f), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			assert.NoError(t, Encode(buf, cm, f))
+
+			img, name, err := image.Decode(buf)
+			assert.NoError(t, err)
+			assert.Equal(t, cm.Bounds(), img.Bounds())
+			assert.Equal(t, strings.TrimPrefix(f.Ext(), "."), strings.Replace(name, "jpeg", "jpg", 1))
+		})
+	}
+
+	parsed, err := ParseFormat("jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, JPEG, parsed)
+	_, err = ParseFormat("bmp")
+	assert.Error(t, err)
+}
+
+// bigMap is a 30x30 labyrinth with rivers, wormholes and items
+func bigMap(b *testing.B) *lab.CellMap {
+	sb := strings.Builder{}
+	sb.WriteString("| X |")
+	for x := 1; x <= 28; x++ {
+		fmt.Fprintf(&sb, " %v |", x)
+	}
+	sb.WriteString("\n|---|" + strings.Repeat("---|", 28) + "\n")
+	for y := 1; y <= 28; y++ {
+		fmt.Fprintf(&sb, "| %v |", y)
+		for x := 1; x <= 28; x++ {
+			code := "  "
+			switch {
+			case x == 28 && y == 1:
+				code = "RM"
+			case y == 1:
+				code = "R"
+			case x%4 == 0 && y%3 == 0:
+				code = "w"
+			case x == 7 && y == 7:
+				code = "W:A:0"
+			case x == 20 && y == 20:
+				code = "W:A:1"
+			}
+			fmt.Fprintf(&sb, " %v |", code)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nexit: 29:28\ntreasure: 5:5\nkey:red: 9:9\n")
+
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, _, err := wb.Build(sb.String())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return &w.Cells
+}
+
+func benchmarkCellMap(b *testing.B) *CellMap {
+	cm, err := NewCellMapImage(bigMap(b), Options{Items: true, Marks: true})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return cm
+}
+
+func BenchmarkRender_PixelByPixel(b *testing.B) {
+	cm := benchmarkCellMap(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		slowRender(cm)
+	}
+}
+
+func BenchmarkRender_Tiles(b *testing.B) {
+	cm := benchmarkCellMap(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Render(cm)
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	cm := benchmarkCellMap(b)
+	for _, f := range Formats {
+		b.Run(string(f), func(b *testing.B) {
+			buf := &bytes.Buffer{}
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				if err := Encode(buf, cm, f); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(buf.Len()), "bytes")
+		})
+	}
+}
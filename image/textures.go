@@ -0,0 +1,270 @@
+package image
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// Names of textures which aren't cell classes
+const (
+	TextureMouth        = "mouth"
+	TextureTreasure     = "treasure"
+	TextureFakeTreasure = "fake-treasure"
+	TextureKey          = "key"
+)
+
+// TextureNames are the textures of a pack. A pack doesn't have to have all of them, missing ones are taken
+// from the default pack
+var TextureNames = []string{
+	lab.CellEarth, lab.CellRiver, TextureMouth, lab.CellWall, lab.CellWormHole, lab.CellExit,
+	TextureTreasure, TextureFakeTreasure, TextureKey,
+}
+
+// ManifestName is the file in a texture pack directory which lists its textures
+const ManifestName = "textures.json"
+
+var textureExtensions = []string{".png", ".jpg", ".jpeg"}
+
+//go:embed textures
+var defaultPack embed.FS
+
+// Manifest lists files of a texture pack, paths are relative to the manifest
+type Manifest struct {
+	// Size is the side of a tile, the earth texture size is used if it's zero
+	Size     int               `json:"size"`
+	Textures map[string]string `json:"textures"`
+}
+
+// Textures are the images cells are drawn with, all of them are squares of the same size
+type Textures struct {
+	Size   int
+	images map[string]*image.RGBA
+}
+
+// Get returns the texture, items are a third of the tile
+func (t *Textures) Get(name string) (*image.RGBA, bool) {
+	img, ok := t.images[name]
+	return img, ok
+}
+
+var defaultSources = sync.OnceValues(func() (map[string]image.Image, error) {
+	return readPackDir(defaultPack, "textures")
+})
+
+var defaultTextures = sync.OnceValues(func() (*Textures, error) {
+	sources, err := defaultSources()
+	if err != nil {
+		return nil, err
+	}
+
+	return newTextures(sources, 0)
+})
+
+// DefaultTextures are built into the binary
+func DefaultTextures() (*Textures, error) {
+	return defaultTextures()
+}
+
+// LoadTextures reads a texture pack. The path is either a manifest or a directory, which has a manifest or
+// textures named after TextureNames, like earth.png or wall.jpg. Textures the pack doesn't have are the default
+// ones, all of them are rescaled to the tile size of the pack
+func LoadTextures(packPath string) (*Textures, error) {
+	info, err := os.Stat(packPath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := packPath
+	if info.IsDir() {
+		manifest = filepath.Join(packPath, ManifestName)
+		if _, err := os.Stat(manifest); err != nil {
+			manifest = ""
+		}
+	}
+
+	var sources map[string]image.Image
+	size := 0
+	if manifest != "" {
+		sources, size, err = readManifest(manifest)
+	} else {
+		sources, err = readPackDir(os.DirFS(packPath), ".")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, err := defaultSources()
+	if err != nil {
+		return nil, err
+	}
+	for name, img := range defaults {
+		if _, ok := sources[name]; !ok {
+			sources[name] = img
+		}
+	}
+
+	if size == 0 {
+		if earth, ok := sources[lab.CellEarth]; ok {
+			size = earth.Bounds().Dx()
+		}
+	}
+
+	return newTextures(sources, size)
+}
+
+func readManifest(manifestPath string) (map[string]image.Image, int, error) {
+	b, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	m := Manifest{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, 0, fmt.Errorf("%v: %w", manifestPath, err)
+	}
+	if m.Size < 0 {
+		return nil, 0, fmt.Errorf("%v: size %v is negative", manifestPath, m.Size)
+	}
+
+	res := map[string]image.Image{}
+	dir := filepath.Dir(manifestPath)
+	for name, file := range m.Textures {
+		if !isTextureName(name) {
+			return nil, 0, fmt.Errorf("%v: unknown texture %v", manifestPath, name)
+		}
+
+		res[name], err = decodeTexture(os.DirFS(dir), filepath.ToSlash(file))
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return res, m.Size, nil
+}
+
+// readPackDir reads textures named after TextureNames, other files are ignored
+func readPackDir(fsys fs.FS, dir string) (map[string]image.Image, error) {
+	res := map[string]image.Image{}
+	for _, name := range TextureNames {
+		for _, ext := range textureExtensions {
+			file := path.Join(dir, name+ext)
+			if _, err := fs.Stat(fsys, file); errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			img, err := decodeTexture(fsys, file)
+			if err != nil {
+				return nil, err
+			}
+			res[name] = img
+			break
+		}
+	}
+
+	return res, nil
+}
+
+func decodeTexture(fsys fs.FS, file string) (image.Image, error) {
+	f, err := fsys.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("texture %v: %w", file, err)
+	}
+	if img.Bounds().Empty() {
+		return nil, fmt.Errorf("texture %v is empty", file)
+	}
+
+	return img, nil
+}
+
+func isTextureName(name string) bool {
+	for _, v := range TextureNames {
+		if v == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isItemTexture(name string) bool {
+	return name == TextureTreasure || name == TextureFakeTreasure || name == TextureKey
+}
+
+// newTextures rescales the images to the tile size, zero size is the size of the earth texture
+func newTextures(sources map[string]image.Image, size int) (*Textures, error) {
+	if size == 0 {
+		earth, ok := sources[lab.CellEarth]
+		if !ok {
+			return nil, fmt.Errorf("there is no %v texture", lab.CellEarth)
+		}
+		size = earth.Bounds().Dx()
+	}
+
+	res := &Textures{Size: size, images: map[string]*image.RGBA{}}
+	for name, img := range sources {
+		side := size
+		if isItemTexture(name) {
+			side = max(1, size/3)
+		}
+		res.images[name] = rescale(img, side)
+	}
+
+	return res, nil
+}
+
+// rescale makes a square image of the given side with bilinear interpolation
+func rescale(src image.Image, side int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	b := src.Bounds()
+	if b.Dx() == side && b.Dy() == side {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+
+	sx := float64(b.Dx()) / float64(side)
+	sy := float64(b.Dy()) / float64(side)
+	for y := 0; y < side; y++ {
+		fy := max(0, (float64(y)+0.5)*sy-0.5)
+		y0 := min(int(fy), b.Dy()-1)
+		y1 := min(y0+1, b.Dy()-1)
+		wy := fy - float64(y0)
+
+		for x := 0; x < side; x++ {
+			fx := max(0, (float64(x)+0.5)*sx-0.5)
+			x0 := min(int(fx), b.Dx()-1)
+			x1 := min(x0+1, b.Dx()-1)
+			wx := fx - float64(x0)
+
+			d := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				top := float64(rgba.Pix[rgba.PixOffset(x0, y0)+c])*(1-wx) + float64(rgba.Pix[rgba.PixOffset(x1, y0)+c])*wx
+				bottom := float64(rgba.Pix[rgba.PixOffset(x0, y1)+c])*(1-wx) + float64(rgba.Pix[rgba.PixOffset(x1, y1)+c])*wx
+				dst.Pix[d+c] = uint8(top*(1-wy) + bottom*wy + 0.5)
+			}
+		}
+	}
+
+	return dst
+}
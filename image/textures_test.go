@@ -0,0 +1,156 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+	md "github.com/kepkin/labyrinth/markdown"
+)
+
+var red = color.RGBA{R: 255, A: 255}
+
+func writePNG(t *testing.T, path string, w, h int, c color.RGBA) {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.NoError(t, png.Encode(f, img))
+}
+
+func TestDefaultTextures(t *testing.T) {
+	textures, err := DefaultTextures()
+	assert.NoError(t, err)
+	assert.Equal(t, 100, textures.Size)
+
+	for _, name := range TextureNames {
+		img, ok := textures.Get(name)
+		if !assert.True(t, ok, name) {
+			continue
+		}
+
+		side := 100
+		if isItemTexture(name) {
+			side = 33
+		}
+		assert.Equal(t, image.Rect(0, 0, side, side), img.Bounds(), name)
+	}
+}
+
+func TestLoadTextures_Dir(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "earth.png"), 20, 20, red)
+	writePNG(t, filepath.Join(dir, "notes.png"), 5, 5, red)
+
+	textures, err := LoadTextures(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, textures.Size, "the tile is as big as the earth of the pack")
+
+	earth, _ := textures.Get(lab.CellEarth)
+	assert.Equal(t, red, earth.RGBAAt(10, 10))
+
+	wall, _ := textures.Get(lab.CellWall)
+	assert.Equal(t, image.Rect(0, 0, 20, 20), wall.Bounds(), "default textures are rescaled")
+
+	key, _ := textures.Get(TextureKey)
+	assert.Equal(t, image.Rect(0, 0, 6, 6), key.Bounds())
+}
+
+func TestLoadTextures_Manifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"rescaled", `{"size": 30, "textures": {"wall": "img/stone.png"}}`, ""},
+		{"unknown texture", `{"textures": {"lava": "img/stone.png"}}`, "unknown texture lava"},
+		{"missing file", `{"textures": {"wall": "img/granite.png"}}`, "granite.png"},
+		{"not an image", `{"textures": {"wall": "textures.json"}}`, "texture textures.json"},
+		{"broken manifest", `{"textures": [}`, "invalid character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			assert.NoError(t, os.Mkdir(filepath.Join(dir, "img"), 0o755))
+			writePNG(t, filepath.Join(dir, "img", "stone.png"), 10, 5, red)
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, ManifestName), []byte(tt.manifest), 0o644))
+
+			// a directory with a manifest and the manifest itself are the same pack
+			for _, path := range []string{dir, filepath.Join(dir, ManifestName)} {
+				textures, err := LoadTextures(path)
+				if tt.wantErr != "" {
+					assert.ErrorContains(t, err, tt.wantErr)
+					continue
+				}
+
+				assert.NoError(t, err)
+				assert.Equal(t, 30, textures.Size)
+				wall, _ := textures.Get(lab.CellWall)
+				assert.Equal(t, image.Rect(0, 0, 30, 30), wall.Bounds())
+				assert.Equal(t, red, wall.RGBAAt(29, 29))
+			}
+		})
+	}
+}
+
+func TestNewCellMapImage(t *testing.T) {
+	dir := t.TempDir()
+	colors := map[string]color.RGBA{
+		lab.CellEarth: {G: 255, A: 255},
+		lab.CellRiver: {B: 255, A: 255},
+		TextureMouth:  {B: 100, A: 255},
+		lab.CellWall:  {R: 100, G: 100, B: 100, A: 255},
+		lab.CellExit:  {R: 255, G: 255, A: 255},
+		TextureKey:    {},
+	}
+	for name, c := range colors {
+		writePNG(t, filepath.Join(dir, name+".png"), 12, 12, c)
+	}
+	writePNG(t, filepath.Join(dir, "treasure.png"), 12, 12, red)
+
+	textures, err := LoadTextures(dir)
+	assert.NoError(t, err)
+
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, _, err := wb.Build(`| X | 1 | 2 | 3  |
+|---|---|---|----|
+| 1 |   | R | RM |
+
+exit: 4:1
+treasure: 1:1
+key:red: 1:1
+`)
+	assert.NoError(t, err)
+
+	at := func(img *CellMap, pos lab.Position, dx, dy int) color.Color {
+		return img.At(pos.X*12+dx, pos.Y*12+dy)
+	}
+
+	plain, err := NewCellMapImage(&w.Cells, Options{Textures: textures})
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 60, 36), plain.Bounds())
+	assert.Equal(t, colors[lab.CellWall], at(plain, lab.NewPosition(0, 0), 0, 0))
+	assert.Equal(t, colors[lab.CellEarth], at(plain, lab.NewPosition(1, 1), 1, 11), "items are hidden")
+	assert.Equal(t, colors[lab.CellRiver], at(plain, lab.NewPosition(2, 1), 0, 0))
+	assert.Equal(t, colors[TextureMouth], at(plain, lab.NewPosition(3, 1), 0, 0))
+	assert.Equal(t, colors[lab.CellExit], at(plain, lab.NewPosition(4, 1), 0, 0))
+
+	master, err := NewCellMapImage(&w.Cells, Options{Textures: textures, Items: true})
+	assert.NoError(t, err)
+	assert.Equal(t, red, at(master, lab.NewPosition(1, 1), 1, 11), "treasure")
+	assert.Equal(t, colors[lab.CellEarth], at(master, lab.NewPosition(1, 1), 5, 11), "the key is transparent")
+	assert.Equal(t, colors[lab.CellEarth], at(master, lab.NewPosition(1, 1), 1, 7), "items are at the bottom")
+}
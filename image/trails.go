@@ -0,0 +1,300 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"slices"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// Grid is a map image which knows where every cell is drawn
+type Grid interface {
+	image.Image
+	CellRect(pos lab.Position) image.Rectangle
+	// Visible tells if the cell is shown, unknown cells of player maps aren't
+	Visible(pos lab.Position) bool
+}
+
+func (cm *CellMap) CellRect(pos lab.Position) image.Rectangle {
+	corner := image.Point{X: pos.X * cm.cellSize.X, Y: pos.Y * cm.cellSize.Y}
+	return image.Rectangle{Min: corner, Max: corner.Add(cm.cellSize)}
+}
+
+func (cm *CellMap) Visible(pos lab.Position) bool {
+	return true
+}
+
+func (pm *PlayerMap) CellRect(pos lab.Position) image.Rectangle {
+	return pm.cmap.CellRect(pos).Sub(pm.cmap.CellRect(pm.pmap.LeftCorner).Min)
+}
+
+func (pm *PlayerMap) Visible(pos lab.Position) bool {
+	_, ok := pm.pmap.KnonwnCells[pos]
+	return ok
+}
+
+// TrailColors tell players apart, the colour of a player is picked by their index in the session
+var TrailColors = []color.RGBA{
+	{R: 230, G: 25, B: 75, A: 255},
+	{R: 0, G: 130, B: 200, A: 255},
+	{R: 145, G: 30, B: 180, A: 255},
+	{R: 245, G: 130, B: 48, A: 255},
+	{R: 70, G: 240, B: 240, A: 255},
+	{R: 240, G: 50, B: 230, A: 255},
+}
+
+var (
+	exitColor         = color.RGBA{R: 60, G: 180, B: 75, A: 255}
+	treasureColor     = color.RGBA{R: 255, G: 215, B: 0, A: 255}
+	fakeTreasureColor = color.RGBA{R: 170, G: 170, B: 170, A: 255}
+	keyColor          = color.RGBA{R: 255, G: 250, B: 200, A: 255}
+	markerEdgeColor   = color.RGBA{A: 255}
+)
+
+// Trails draws paths of players over a map: walks as solid lines, river drags dashed and teleports as arcs.
+// Start and current positions of players, items and exits are marked.
+type Trails struct {
+	base  Grid
+	layer *image.RGBA
+}
+
+// NewTrails draws trails of the players from the session history. If names are given only these players are drawn.
+// Only what's visible on the base is drawn, so a player map keeps its fog.
+func NewTrails(base Grid, s *lab.Session, names ...string) *Trails {
+	return newTrails(base, s, true, names)
+}
+
+// NewPlayerTrails works like NewTrails over a player's map: items aren't drawn, players don't see them
+func NewPlayerTrails(base Grid, s *lab.Session, names ...string) *Trails {
+	return newTrails(base, s, false, names)
+}
+
+func newTrails(base Grid, s *lab.Session, items bool, names []string) *Trails {
+	t := &Trails{
+		base:  base,
+		layer: image.NewRGBA(base.Bounds()),
+	}
+
+	t.drawExits(s.World, items)
+
+	cell := base.CellRect(lab.Position{}).Size()
+	width := max(2, cell.X/12)
+	for idx, p := range s.Players {
+		if len(names) > 0 && !slices.Contains(names, p.Name) {
+			continue
+		}
+
+		// players are shifted a bit, so paths along the same cells don't hide each other
+		shift := (idx%len(TrailColors) - len(TrailColors)/2) * width
+		c := TrailColors[idx%len(TrailColors)]
+
+		start := p.Pos
+		started := false
+		for _, st := range s.History {
+			if st.Player != p.Name {
+				continue
+			}
+			if !started {
+				start, started = st.From, true
+			}
+
+			for _, seg := range st.Segments(s.World) {
+				if !base.Visible(seg.From) || !base.Visible(seg.To) {
+					continue
+				}
+				t.drawSegment(seg, shift, width, c)
+			}
+		}
+
+		if base.Visible(start) {
+			t.ring(t.center(start, shift), cell.X/5, width, c)
+		}
+		if base.Visible(p.Pos) {
+			t.disc(t.center(p.Pos, shift), cell.X/5+width, markerEdgeColor)
+			t.disc(t.center(p.Pos, shift), cell.X/5, c)
+		}
+	}
+
+	return t
+}
+
+func (t *Trails) Bounds() image.Rectangle {
+	return t.base.Bounds()
+}
+
+func (t *Trails) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (t *Trails) At(x, y int) color.Color {
+	over := t.layer.RGBAAt(x, y)
+	if over.A == 0 {
+		return t.base.At(x, y)
+	}
+	if over.A == 255 {
+		return over
+	}
+
+	r, g, b, _ := t.base.At(x, y).RGBA()
+	blend := func(o uint8, under uint32) uint8 {
+		return uint8((uint32(o)*255 + (under>>8)*uint32(255-over.A)) / 255)
+	}
+
+	return color.RGBA{R: blend(over.R, r), G: blend(over.G, g), B: blend(over.B, b), A: 255}
+}
+
+func (t *Trails) center(pos lab.Position, shift int) image.Point {
+	r := t.base.CellRect(pos)
+	return image.Point{X: (r.Min.X+r.Max.X)/2 + shift, Y: (r.Min.Y+r.Max.Y)/2 + shift}
+}
+
+// drawExits marks exits and, if asked, items lying in cells
+func (t *Trails) drawExits(w *lab.World, items bool) {
+	for pos, c := range w.Cells.All() {
+		if !t.base.Visible(pos) {
+			continue
+		}
+
+		r := t.base.CellRect(pos)
+		if c.Class == lab.CellExit {
+			inset := r.Dx() / 8
+			t.frame(r.Inset(inset), max(2, r.Dx()/16), exitColor)
+		}
+
+		if !items {
+			continue
+		}
+
+		for i, item := range c.Items {
+			size := r.Dx() / 6
+			corner := image.Point{X: r.Min.X + size/2 + i*(size+2), Y: r.Max.Y - size - size/2}
+			t.diamond(corner, size, itemColor(item))
+		}
+	}
+}
+
+func itemColor(item *lab.Item) color.RGBA {
+	switch item.ID {
+	case lab.Treasure:
+		return treasureColor
+	case lab.FakeTreasure:
+		return fakeTreasureColor
+	}
+
+	return keyColor
+}
+
+func (t *Trails) drawSegment(seg lab.Segment, shift int, width int, c color.RGBA) {
+	from, to := t.center(seg.From, shift), t.center(seg.To, shift)
+
+	switch seg.Kind {
+	case lab.DragSegment:
+		t.line(from, to, width, c, width*3)
+	case lab.JumpSegment:
+		t.arc(from, to, width, c)
+	default:
+		t.line(from, to, width, c, 0)
+	}
+}
+
+func (t *Trails) dot(p image.Point, width int, c color.RGBA) {
+	r := image.Rect(p.X-width/2, p.Y-width/2, p.X-width/2+width, p.Y-width/2+width)
+	draw.Draw(t.layer, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// line draws a line of the given width. If dash isn't zero the line is dashed with dashes and gaps of this length
+func (t *Trails) line(from, to image.Point, width int, c color.RGBA, dash int) {
+	d := to.Sub(from)
+	steps := max(abs(d.X), abs(d.Y))
+	for i := 0; i <= steps; i++ {
+		if dash > 0 && (i/dash)%2 == 1 {
+			continue
+		}
+
+		p := from
+		if steps > 0 {
+			p = from.Add(d.Mul(i).Div(steps))
+		}
+		t.dot(p, width, c)
+	}
+}
+
+// arc draws a quadratic curve from one cell to another which bends to the left of the jump
+func (t *Trails) arc(from, to image.Point, width int, c color.RGBA) {
+	d := to.Sub(from)
+	length := math.Hypot(float64(d.X), float64(d.Y))
+	if length == 0 {
+		return
+	}
+
+	mid := image.Point{X: (from.X + to.X) / 2, Y: (from.Y + to.Y) / 2}
+	bend := length / 3
+	ctrl := image.Point{
+		X: mid.X + int(float64(d.Y)/length*bend),
+		Y: mid.Y - int(float64(d.X)/length*bend),
+	}
+
+	steps := int(length * 1.5)
+	for i := 0; i <= steps; i++ {
+		k := float64(i) / float64(steps)
+		x := (1-k)*(1-k)*float64(from.X) + 2*(1-k)*k*float64(ctrl.X) + k*k*float64(to.X)
+		y := (1-k)*(1-k)*float64(from.Y) + 2*(1-k)*k*float64(ctrl.Y) + k*k*float64(to.Y)
+		t.dot(image.Point{X: int(x), Y: int(y)}, width, c)
+	}
+
+	// arrow head at the end
+	t.disc(to, width*2, c)
+}
+
+func (t *Trails) disc(center image.Point, radius int, c color.RGBA) {
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				t.layer.SetRGBA(center.X+x, center.Y+y, c)
+			}
+		}
+	}
+}
+
+func (t *Trails) ring(center image.Point, radius int, width int, c color.RGBA) {
+	inner := (radius - width) * (radius - width)
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if d := x*x + y*y; d <= radius*radius && d >= inner {
+				t.layer.SetRGBA(center.X+x, center.Y+y, c)
+			}
+		}
+	}
+}
+
+func (t *Trails) frame(r image.Rectangle, width int, c color.RGBA) {
+	u := image.NewUniform(c)
+	draw.Draw(t.layer, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+width), u, image.Point{}, draw.Src)
+	draw.Draw(t.layer, image.Rect(r.Min.X, r.Max.Y-width, r.Max.X, r.Max.Y), u, image.Point{}, draw.Src)
+	draw.Draw(t.layer, image.Rect(r.Min.X, r.Min.Y, r.Min.X+width, r.Max.Y), u, image.Point{}, draw.Src)
+	draw.Draw(t.layer, image.Rect(r.Max.X-width, r.Min.Y, r.Max.X, r.Max.Y), u, image.Point{}, draw.Src)
+}
+
+// diamond draws an item marker with its top left corner at p
+func (t *Trails) diamond(p image.Point, size int, c color.RGBA) {
+	half := size / 2
+	center := p.Add(image.Point{X: half, Y: half})
+	for y := -half; y <= half; y++ {
+		for x := -half; x <= half; x++ {
+			if abs(x)+abs(y) <= half {
+				t.layer.SetRGBA(center.X+x, center.Y+y, c)
+			}
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
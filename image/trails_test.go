@@ -0,0 +1,97 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// plainGrid is a white map with 24px cells
+type plainGrid struct {
+	cols, rows int
+	known      map[lab.Position]bool
+}
+
+func (g plainGrid) Bounds() image.Rectangle       { return image.Rect(0, 0, g.cols*24, g.rows*24) }
+func (g plainGrid) ColorModel() color.Model       { return color.RGBAModel }
+func (g plainGrid) At(x, y int) color.Color       { return color.White }
+func (g plainGrid) Visible(pos lab.Position) bool { return g.known == nil || g.known[pos] }
+func (g plainGrid) CellRect(pos lab.Position) image.Rectangle {
+	return image.Rect(pos.X*24, pos.Y*24, pos.X*24+24, pos.Y*24+24)
+}
+
+func playedSession(t *testing.T) *lab.Session {
+	w := lab.NewWorldFromString(`
+wwwwww
+w  ↓ w
+w  ↓ w
+w  ↓ w
+wwwwww
+`)
+	s := &lab.Session{World: w}
+	s.AddPlayer("alex", lab.NewPosition(1, 1))
+	s.Do("east")
+	s.Do("east")
+
+	assert.Equal(t, lab.NewPosition(3, 3), s.Players[0].Pos)
+	return s
+}
+
+func TestTrails(t *testing.T) {
+	s := playedSession(t)
+	tr := NewTrails(plainGrid{cols: 6, rows: 5}, s)
+
+	alex := TrailColors[0]
+	shift := -3 * 2
+	at := func(pos lab.Position, dx, dy int) color.Color {
+		return tr.At(pos.X*24+12+shift+dx, pos.Y*24+12+shift+dy)
+	}
+
+	assert.Equal(t, alex, at(lab.NewPosition(1, 1), 4, 0), "start ring")
+	assert.Equal(t, alex, at(lab.NewPosition(2, 1), 0, 0), "walk is solid")
+	assert.Equal(t, alex, at(lab.NewPosition(3, 3), 0, 0), "current position")
+	assert.Equal(t, color.White, at(lab.NewPosition(4, 3), 0, 0))
+
+	dashed := 0
+	for y := 0; y < 24; y++ {
+		if at(lab.NewPosition(3, 1), 0, 12+y) == color.Color(alex) {
+			dashed++
+		}
+	}
+	assert.Greater(t, dashed, 6, "river drag is drawn")
+	assert.Less(t, dashed, 18, "river drag is dashed")
+}
+
+func TestTrails_KeepsFog(t *testing.T) {
+	s := playedSession(t)
+	known := map[lab.Position]bool{lab.NewPosition(1, 1): true, lab.NewPosition(2, 1): true}
+	tr := NewTrails(plainGrid{cols: 6, rows: 5, known: known}, s)
+
+	assert.Equal(t, color.Color(TrailColors[0]), tr.At(1*24+12-6+12, 1*24+12-6))
+	assert.Equal(t, color.Color(color.White), tr.At(3*24+12-6, 3*24+12-6))
+}
+
+func TestPlayerTrails_HidesItems(t *testing.T) {
+	s := playedSession(t)
+	s.World.Cells.Get(lab.NewPosition(2, 1)).PutItem(&lab.Item{ID: lab.Treasure, Name: "tresure"})
+
+	treasurePixels := func(tr *Trails) int {
+		res := 0
+		for y := 24; y < 48; y++ {
+			for x := 48; x < 72; x++ {
+				if tr.At(x, y) == color.Color(treasureColor) {
+					res++
+				}
+			}
+		}
+
+		return res
+	}
+
+	assert.Positive(t, treasurePixels(NewTrails(plainGrid{cols: 6, rows: 5}, s)))
+	assert.Zero(t, treasurePixels(NewPlayerTrails(plainGrid{cols: 6, rows: 5}, s)))
+}
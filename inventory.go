@@ -0,0 +1,79 @@
+package labyrinth
+
+import "slices"
+
+const DefaultInventoryCapacity = 2
+
+// Inventory holds items a player carries. Zero Capacity means unlimited.
+type Inventory struct {
+	Items    []*Item
+	Capacity int
+}
+
+func NewInventory(capacity int) Inventory {
+	return Inventory{Capacity: capacity}
+}
+
+func (inv *Inventory) IsFull() bool {
+	return inv.Capacity > 0 && len(inv.Items) >= inv.Capacity
+}
+
+func (inv *Inventory) IsEmpty() bool {
+	return len(inv.Items) == 0
+}
+
+func (inv *Inventory) Put(e *Item) bool {
+	if e == nil || inv.IsFull() {
+		return false
+	}
+
+	inv.Items = append(inv.Items, e)
+	return true
+}
+
+func (inv *Inventory) Take(name string) *Item {
+	var result *Item
+	inv.Items = slices.DeleteFunc(inv.Items, func(e *Item) bool {
+		if result != nil || e.Name != name {
+			return false
+		}
+
+		result = e
+		return true
+	})
+
+	return result
+}
+
+func (inv *Inventory) Remove(e *Item) {
+	inv.Items = slices.DeleteFunc(inv.Items, func(v *Item) bool {
+		return v == e
+	})
+}
+
+// TakeAll empties the inventory and returns everything it held
+func (inv *Inventory) TakeAll() []*Item {
+	res := inv.Items
+	inv.Items = nil
+	return res
+}
+
+func (inv *Inventory) Find(id HandItem) *Item {
+	for _, v := range inv.Items {
+		if v.ID == id {
+			return v
+		}
+	}
+
+	return nil
+}
+
+func (inv *Inventory) HasKey(tag string) bool {
+	for _, v := range inv.Items {
+		if v.ID == Key && v.Tag == tag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,354 @@
+package labyrinth
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// Problem is something that makes a map unplayable. Pos is zero for problems of the whole map
+type Problem struct {
+	Pos  lab.Position
+	Text string
+}
+
+func (p Problem) String() string {
+	if p.Pos == (lab.Position{}) {
+		return p.Text
+	}
+
+	return fmt.Sprintf("%v: %v", p.Pos, p.Text)
+}
+
+// checker collects problems and remembers what can't be built
+type checker struct {
+	d        *Document
+	problems []Problem
+
+	badCells map[lab.Position]bool
+	// badRivers are river cells without a computable flow
+	badRivers map[lab.Position]bool
+	badProps  map[int]bool
+}
+
+func (c *checker) add(pos lab.Position, format string, args ...any) {
+	c.problems = append(c.problems, Problem{Pos: pos, Text: fmt.Sprintf(format, args...)})
+}
+
+// Check validates the map: cell codes, rivers, wormholes, doors and properties
+func (d *Document) Check() []Problem {
+	return d.check().problems
+}
+
+func (d *Document) check() *checker {
+	c := &checker{
+		d:         d,
+		badCells:  map[lab.Position]bool{},
+		badRivers: map[lab.Position]bool{},
+		badProps:  map[int]bool{},
+	}
+
+	c.checkCodes()
+	c.checkRivers()
+	c.checkWormholes()
+	c.checkProperties()
+	c.checkDoors()
+
+	return c
+}
+
+func (c *checker) inner() []lab.Position {
+	var res []lab.Position
+	for y := 1; y <= c.d.Height(); y++ {
+		for x := 1; x <= c.d.Width(); x++ {
+			res = append(res, lab.NewPosition(x, y))
+		}
+	}
+
+	return res
+}
+
+func (c *checker) checkCodes() {
+	fac := lab.NewDefaultCellFactory()
+	for _, pos := range c.inner() {
+		code := c.d.Code(pos)
+
+		var err error
+		switch key, _, _ := strings.Cut(code, ":"); key {
+		case "W":
+			_, _, err = wormholeCode(code)
+		case "D":
+			parts := strings.Split(code, ":")
+			if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+				err = fmt.Errorf("door must have a key name, like `D:red`")
+			}
+		default:
+			_, err = fac.Make(code, pos)
+		}
+
+		if err != nil {
+			c.add(pos, "wrong cell `%v`: %v", code, err)
+			c.badCells[pos] = true
+		}
+	}
+}
+
+// wormholeCode reads `W:<system>:<index>`
+func wormholeCode(code string) (string, int, error) {
+	parts := strings.Split(code, ":")
+	if len(parts) != 3 || strings.TrimSpace(parts[1]) == "" {
+		return "", 0, fmt.Errorf("wormhole must have a system and an index, like `W:A:0`")
+	}
+
+	idx, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+	if err != nil || idx < 0 {
+		return "", 0, fmt.Errorf("wormhole index must be a number")
+	}
+
+	return strings.TrimSpace(parts[1]), idx, nil
+}
+
+func (c *checker) isRiver(pos lab.Position) bool {
+	return c.d.IsInside(pos) && slices.Contains(lab.RiverStringFactoryKeys, c.d.Code(pos))
+}
+
+func (c *checker) riverNeighbours(pos lab.Position) []lab.Position {
+	var res []lab.Position
+	for _, dir := range []lab.MoveDirection{lab.North, lab.East, lab.South, lab.West} {
+		if c.isRiver(pos.Next(dir)) {
+			res = append(res, pos.Next(dir))
+		}
+	}
+
+	return res
+}
+
+// checkRivers makes sure the flow of every river can be found from its mouth:
+// a river is a line of cells without branches and loops and with exactly one mouth at its end.
+// Rivers drawn only with arrows already have the flow and aren't checked.
+func (c *checker) checkRivers() {
+	visited := map[lab.Position]bool{}
+	for _, start := range c.inner() {
+		if !c.isRiver(start) || visited[start] {
+			continue
+		}
+
+		var river []lab.Position
+		queue := []lab.Position{start}
+		visited[start] = true
+		for len(queue) > 0 {
+			pos := queue[0]
+			queue = queue[1:]
+			river = append(river, pos)
+
+			for _, next := range c.riverNeighbours(pos) {
+				if !visited[next] {
+					visited[next] = true
+					queue = append(queue, next)
+				}
+			}
+		}
+
+		if !slices.ContainsFunc(river, func(pos lab.Position) bool { return lab.MoveDirectionFromUtf8Arrow(c.d.Code(pos)) == lab.MoveNil }) {
+			continue
+		}
+
+		if problem := c.riverProblem(river); problem != "" {
+			c.add(river[0], "%v", problem)
+			for _, pos := range river {
+				c.badRivers[pos] = true
+			}
+		}
+	}
+}
+
+func (c *checker) riverProblem(river []lab.Position) string {
+	links := 0
+	var mouths []lab.Position
+	for _, pos := range river {
+		neighbours := len(c.riverNeighbours(pos))
+		if neighbours > 2 {
+			return fmt.Sprintf("river branches at %v", pos)
+		}
+		links += neighbours
+
+		if c.d.Code(pos) == "RM" {
+			mouths = append(mouths, pos)
+		}
+	}
+
+	if links/2 >= len(river) {
+		return "river is a loop"
+	}
+
+	if len(river) < 2 {
+		return "river must be at least two cells long"
+	}
+
+	if len(mouths) != 1 {
+		return fmt.Sprintf("river must have exactly one mouth (RM), it has %v", len(mouths))
+	}
+
+	if len(c.riverNeighbours(mouths[0])) != 1 {
+		return fmt.Sprintf("river mouth at %v is not at the end of the river", mouths[0])
+	}
+
+	return ""
+}
+
+func (c *checker) checkWormholes() {
+	systems := map[string]map[int][]lab.Position{}
+	var names []string
+	for _, pos := range c.inner() {
+		code := c.d.Code(pos)
+		if !strings.HasPrefix(code, "W:") || c.badCells[pos] {
+			continue
+		}
+
+		name, idx, _ := wormholeCode(code)
+		if systems[name] == nil {
+			systems[name] = map[int][]lab.Position{}
+			names = append(names, name)
+		}
+		systems[name][idx] = append(systems[name][idx], pos)
+	}
+
+	for _, name := range names {
+		holes := systems[name]
+		indexes := slices.Sorted(maps.Keys(holes))
+		if len(holes) < 2 {
+			c.add(holes[indexes[0]][0], "wormhole system %v has only one hole", name)
+		}
+
+		for _, idx := range indexes {
+			if positions := holes[idx]; len(positions) > 1 {
+				c.add(positions[1], "wormhole %v:%v is used twice", name, idx)
+			}
+		}
+
+		for idx := range len(holes) {
+			if _, ok := holes[idx]; !ok {
+				c.add(lab.Position{}, "wormhole system %v misses index %v", name, idx)
+			}
+		}
+	}
+}
+
+func (c *checker) isWall(pos lab.Position) bool {
+	code := c.d.Code(pos)
+	return code == "w" || code == lab.CellWall
+}
+
+func (c *checker) checkProperties() {
+	exits, treasures := 0, 0
+	players := map[string]bool{}
+	for i, prop := range c.d.Properties {
+		var err error
+		switch prop.Name {
+		case "exit":
+			exits++
+			if !c.d.IsOnOuterWall(prop.Pos) {
+				err = fmt.Errorf("exit must be on the outer wall")
+			}
+		case "treasure":
+			treasures++
+			_, err = treasureValue(prop)
+		case "fake_treasure":
+		case "key":
+			if len(prop.Args) != 1 || prop.Args[0] == "" {
+				err = fmt.Errorf("key must have exactly one name")
+			}
+		case "minotaur":
+			_, err = makeMonster(prop)
+		default:
+			if players[prop.Name] {
+				err = fmt.Errorf("player %v is already on the map", prop.Name)
+			}
+			players[prop.Name] = true
+		}
+
+		if err == nil && prop.Name != "exit" && (!c.d.IsInside(prop.Pos) || c.isWall(prop.Pos)) {
+			err = fmt.Errorf("%v must be on a free cell inside the maze", prop.Name)
+		}
+
+		if err != nil {
+			c.add(prop.Pos, "%v", err)
+			c.badProps[i] = true
+		}
+	}
+
+	if exits == 0 {
+		c.add(lab.Position{}, "there is no exit")
+	}
+	if treasures == 0 {
+		c.add(lab.Position{}, "there is no treasure")
+	}
+	if len(players) == 0 {
+		c.add(lab.Position{}, "there are no players")
+	}
+}
+
+func (c *checker) checkDoors() {
+	keys := map[string]bool{}
+	for _, prop := range c.d.Properties {
+		if prop.Name == "key" && len(prop.Args) == 1 {
+			keys[prop.Args[0]] = true
+		}
+	}
+
+	for _, pos := range c.inner() {
+		code := c.d.Code(pos)
+		if !strings.HasPrefix(code, "D:") || c.badCells[pos] {
+			continue
+		}
+
+		if key := strings.TrimSpace(strings.TrimPrefix(code, "D:")); !keys[key] {
+			c.add(pos, "there is no key `%v` for the door", key)
+		}
+	}
+}
+
+// Preview builds the world even if the map has problems. Wrong cells are built as earth,
+// rivers without computable flow have no direction and wrong properties are skipped.
+func (d *Document) Preview() (*lab.World, []*lab.Player, []Problem) {
+	c := d.check()
+
+	safe := NewDocument(d.Width(), d.Height())
+	for _, pos := range c.inner() {
+		if !c.badCells[pos] && !c.badRivers[pos] {
+			_ = safe.SetCode(pos, d.Code(pos))
+		}
+	}
+	for i, prop := range d.Properties {
+		if !c.badProps[i] {
+			safe.Properties = append(safe.Properties, prop)
+		}
+	}
+
+	wb := WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, pls, err := wb.Build(safe.String())
+	if err != nil {
+		c.add(lab.Position{}, "%v", err)
+		return &lab.World{}, nil, c.problems
+	}
+
+	for pos := range c.badRivers {
+		w.Cells.Insert(&lab.CellType{Class: "river", Custom: &lab.RiverCell{}}, pos)
+	}
+
+	return w, pls, c.problems
+}
+
+// Build builds the world with a fresh cell factory, so it can be called many times
+func (d *Document) Build() (*lab.World, []*lab.Player, error) {
+	if problems := d.Check(); len(problems) > 0 {
+		return nil, nil, fmt.Errorf("%v", problems[0])
+	}
+
+	wb := WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	return wb.Build(d.String())
+}
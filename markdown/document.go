@@ -0,0 +1,195 @@
+package labyrinth
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// Document is a map the way it's written in markdown: cell codes and properties.
+// Unlike WorldBuilder it keeps the codes, so a map can be edited and written back.
+type Document struct {
+	// Cells are codes of the maze without the outer walls, Cells[y-1][x-1] is the code of cell x:y
+	Cells      [][]string
+	Properties []Property
+}
+
+func NewDocument(width, height int) *Document {
+	d := &Document{}
+	d.Resize(width, height)
+
+	return d
+}
+
+// codeRecorder is a cell factory which accepts any code and remembers it
+type codeRecorder struct {
+	codes map[lab.Position]string
+}
+
+func (r *codeRecorder) Make(key string, pos lab.Position) (lab.Cell, error) {
+	r.codes[pos] = key
+	return &lab.CellType{Class: lab.CellEarth}, nil
+}
+
+func (r *codeRecorder) Finish(cm lab.CellMap) error {
+	return nil
+}
+
+// ReadDocument reads a map without building it, so cells with wrong codes don't stop reading
+func ReadDocument(src string) (*Document, error) {
+	rec := &codeRecorder{codes: map[lab.Position]string{}}
+	wb := WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: rec}}
+
+	w, _, err := wb.Build(src)
+	if err != nil {
+		return nil, err
+	}
+
+	size := w.Dimensions()
+	d := NewDocument(size.Width-2, size.Height-2)
+	for y := range d.Cells {
+		for x := range d.Cells[y] {
+			d.Cells[y][x] = rec.codes[lab.NewPosition(x+1, y+1)]
+		}
+	}
+	d.Properties = wb.properties
+
+	return d, nil
+}
+
+func (d *Document) Width() int {
+	if len(d.Cells) == 0 {
+		return 0
+	}
+
+	return len(d.Cells[0])
+}
+
+func (d *Document) Height() int {
+	return len(d.Cells)
+}
+
+// Resize keeps codes of cells which are still inside the maze. Properties are not touched
+func (d *Document) Resize(width, height int) {
+	cells := make([][]string, height)
+	for y := range cells {
+		cells[y] = make([]string, width)
+		if y < len(d.Cells) {
+			copy(cells[y], d.Cells[y])
+		}
+	}
+
+	d.Cells = cells
+}
+
+// IsInside tells if pos is inside the maze, not on the outer walls
+func (d *Document) IsInside(pos lab.Position) bool {
+	return pos.X >= 1 && pos.Y >= 1 && pos.X <= d.Width() && pos.Y <= d.Height()
+}
+
+// IsOnOuterWall tells if pos is on the outer walls, corners excluded. Exits are placed there
+func (d *Document) IsOnOuterWall(pos lab.Position) bool {
+	onVertical := (pos.X == 0 || pos.X == d.Width()+1) && pos.Y >= 1 && pos.Y <= d.Height()
+	onHorizontal := (pos.Y == 0 || pos.Y == d.Height()+1) && pos.X >= 1 && pos.X <= d.Width()
+
+	return onVertical || onHorizontal
+}
+
+// Code returns the code of a cell, outer walls are "w"
+func (d *Document) Code(pos lab.Position) string {
+	if !d.IsInside(pos) {
+		return "w"
+	}
+
+	return d.Cells[pos.Y-1][pos.X-1]
+}
+
+func (d *Document) SetCode(pos lab.Position, code string) error {
+	if !d.IsInside(pos) {
+		return fmt.Errorf("%v is not inside the maze", pos)
+	}
+
+	d.Cells[pos.Y-1][pos.X-1] = strings.TrimSpace(code)
+	return nil
+}
+
+func (d *Document) PropertiesAt(pos lab.Position) []Property {
+	var res []Property
+	for _, v := range d.Properties {
+		if v.Pos == pos {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+func (d *Document) RemovePropertiesAt(pos lab.Position) {
+	d.Properties = slices.DeleteFunc(d.Properties, func(p Property) bool { return p.Pos == pos })
+}
+
+func (p Property) String() string {
+	return fmt.Sprintf("%v: %v", strings.Join(append([]string{p.Name}, p.Args...), ":"), p.Pos)
+}
+
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
+
+// Write writes the map as a formatted markdown table followed by properties
+func (d *Document) Write(w io.Writer) error {
+	widths := make([]int, d.Width()+1)
+	widths[0] = max(1, len(fmt.Sprint(d.Height())))
+	for x := 1; x <= d.Width(); x++ {
+		widths[x] = len(fmt.Sprint(x))
+		for y := range d.Cells {
+			widths[x] = max(widths[x], utf8.RuneCountInString(d.Cells[y][x-1]))
+		}
+	}
+
+	sb := strings.Builder{}
+	writeRow := func(values []string) {
+		for i, v := range values {
+			sb.WriteString("| ")
+			sb.WriteString(pad(v, widths[i]))
+			sb.WriteString(" ")
+		}
+		sb.WriteString("|\n")
+	}
+
+	header := []string{"X"}
+	for x := 1; x <= d.Width(); x++ {
+		header = append(header, fmt.Sprint(x))
+	}
+	writeRow(header)
+
+	for _, width := range widths {
+		sb.WriteString("|")
+		sb.WriteString(strings.Repeat("-", width+2))
+	}
+	sb.WriteString("|\n")
+
+	for y, row := range d.Cells {
+		writeRow(append([]string{fmt.Sprint(y + 1)}, row...))
+	}
+
+	sb.WriteString("\n")
+	for _, v := range d.Properties {
+		sb.WriteString(v.String())
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (d *Document) String() string {
+	sb := strings.Builder{}
+	_ = d.Write(&sb)
+
+	return sb.String()
+}
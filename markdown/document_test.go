@@ -0,0 +1,162 @@
+package labyrinth
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+	"github.com/kepkin/labyrinth/text"
+)
+
+func TestDocument_RoundTrip(t *testing.T) {
+	src, err := os.ReadFile("../examples/lab-map1.md")
+	assert.NoError(t, err)
+
+	d, err := ReadDocument(string(src))
+	assert.NoError(t, err)
+	assert.Equal(t, 8, d.Width())
+	assert.Equal(t, 8, d.Height())
+	assert.Equal(t, "W:A:1", d.Code(lab.NewPosition(3, 1)))
+	assert.Equal(t, "RM", d.Code(lab.NewPosition(5, 3)))
+	assert.Equal(t, Property{Name: "exit", Args: []string{}, Pos: lab.NewPosition(9, 8)}, d.Properties[0])
+	assert.Empty(t, d.Check())
+
+	again, err := ReadDocument(d.String())
+	assert.NoError(t, err)
+	assert.Equal(t, d, again)
+
+	w, pls, err := again.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, lab.CellExit, w.Cells.Get(lab.NewPosition(9, 8)).Class)
+	assert.Equal(t, lab.West, w.Cells.Get(lab.NewPosition(6, 3)).Custom.(*lab.RiverCell).Dir)
+	assert.Len(t, pls, 2)
+}
+
+func TestDocument_Write(t *testing.T) {
+	d := NewDocument(3, 2)
+	_ = d.SetCode(lab.NewPosition(2, 1), "W:A:0")
+	d.Properties = []Property{{Name: "exit", Pos: lab.NewPosition(4, 2)}, {Name: "alex", Args: []string{"red"}, Pos: lab.NewPosition(1, 1)}}
+
+	assert.Equal(t, `| X | 1 | 2     | 3 |
+|---|---|-------|---|
+| 1 |   | W:A:0 |   |
+| 2 |   |       |   |
+
+exit: 4:2
+alex:red: 1:1
+`, d.String())
+}
+
+func TestDocument_Check(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells map[lab.Position]string
+		props []Property
+		want  []Problem
+	}{
+		{
+			name:  "empty map",
+			props: []Property{},
+			want:  []Problem{{Text: "there is no exit"}, {Text: "there is no treasure"}, {Text: "there are no players"}},
+		},
+		{
+			name:  "wrong codes",
+			cells: map[lab.Position]string{{X: 1, Y: 1}: "Q", {X: 2, Y: 1}: "W:A", {X: 3, Y: 1}: "D"},
+			want: []Problem{
+				{Pos: lab.NewPosition(1, 1), Text: "wrong cell `Q`: no factory for this cell Q"},
+				{Pos: lab.NewPosition(2, 1), Text: "wrong cell `W:A`: wormhole must have a system and an index, like `W:A:0`"},
+				{Pos: lab.NewPosition(3, 1), Text: "wrong cell `D`: door must have a key name, like `D:red`"},
+			},
+		},
+		{
+			name:  "river loop",
+			cells: map[lab.Position]string{{X: 1, Y: 1}: "R", {X: 2, Y: 1}: "RM", {X: 1, Y: 2}: "R", {X: 2, Y: 2}: "R"},
+			want:  []Problem{{Pos: lab.NewPosition(1, 1), Text: "river is a loop"}},
+		},
+		{
+			name:  "river without mouth",
+			cells: map[lab.Position]string{{X: 1, Y: 1}: "R", {X: 2, Y: 1}: "R"},
+			want:  []Problem{{Pos: lab.NewPosition(1, 1), Text: "river must have exactly one mouth (RM), it has 0"}},
+		},
+		{
+			name:  "mouth in the middle",
+			cells: map[lab.Position]string{{X: 1, Y: 1}: "R", {X: 2, Y: 1}: "RM", {X: 3, Y: 1}: "R"},
+			want:  []Problem{{Pos: lab.NewPosition(1, 1), Text: "river mouth at 2:1 is not at the end of the river"}},
+		},
+		{
+			name:  "river of arrows",
+			cells: map[lab.Position]string{{X: 1, Y: 1}: "→", {X: 2, Y: 1}: "→", {X: 3, Y: 1}: "↓", {X: 3, Y: 2}: "←"},
+		},
+		{
+			name:  "wormholes",
+			cells: map[lab.Position]string{{X: 1, Y: 1}: "W:A:0", {X: 2, Y: 1}: "W:A:2", {X: 3, Y: 1}: "W:B:0"},
+			want: []Problem{
+				{Text: "wormhole system A misses index 1"},
+				{Pos: lab.NewPosition(3, 1), Text: "wormhole system B has only one hole"},
+			},
+		},
+		{
+			name:  "door without key",
+			cells: map[lab.Position]string{{X: 1, Y: 1}: "D:red"},
+			want:  []Problem{{Pos: lab.NewPosition(1, 1), Text: "there is no key `red` for the door"}},
+		},
+		{
+			name:  "properties",
+			cells: map[lab.Position]string{{X: 1, Y: 1}: "w"},
+			props: []Property{
+				{Name: "exit", Pos: lab.NewPosition(2, 2)},
+				{Name: "treasure", Args: []string{"a lot"}, Pos: lab.NewPosition(2, 1)},
+				{Name: "alex", Pos: lab.NewPosition(1, 1)},
+				{Name: "exit", Pos: lab.NewPosition(0, 3)},
+			},
+			want: []Problem{
+				{Pos: lab.NewPosition(2, 2), Text: "exit must be on the outer wall"},
+				{Pos: lab.NewPosition(2, 1), Text: "property treasure has incorrect value: `a lot`"},
+				{Pos: lab.NewPosition(1, 1), Text: "alex must be on a free cell inside the maze"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDocument(3, 3)
+			for pos, code := range tt.cells {
+				assert.NoError(t, d.SetCode(pos, code))
+			}
+
+			d.Properties = tt.props
+			if d.Properties == nil {
+				d.Properties = []Property{
+					{Name: "exit", Pos: lab.NewPosition(0, 3)},
+					{Name: "treasure", Pos: lab.NewPosition(2, 3)},
+					{Name: "alex", Pos: lab.NewPosition(1, 3)},
+				}
+			}
+
+			assert.Equal(t, tt.want, d.Check())
+		})
+	}
+}
+
+func TestDocument_PreviewBrokenRiver(t *testing.T) {
+	d := NewDocument(3, 3)
+	_ = d.SetCode(lab.NewPosition(1, 1), "R")
+	_ = d.SetCode(lab.NewPosition(2, 1), "R")
+	_ = d.SetCode(lab.NewPosition(3, 1), "Q")
+
+	w, _, problems := d.Preview()
+
+	assert.Len(t, problems, 5)
+	assert.Equal(t, "river", w.Cells.Get(lab.NewPosition(1, 1)).Class)
+	assert.Equal(t, lab.MoveNil, w.Cells.Get(lab.NewPosition(1, 1)).Custom.(*lab.RiverCell).Dir)
+	assert.Equal(t, lab.CellEarth, w.Cells.Get(lab.NewPosition(3, 1)).Class)
+	// the broken river is two mouths, the unknown code is earth
+	assert.Equal(t, `#####
+#OO.#
+#...#
+#...#
+#####
+`, text.String(&w.Cells, text.Options{}))
+}
@@ -125,7 +125,7 @@ func (h *namePosReader) next(c rune) (bool, error) {
 			return false, nil
 		}
 
-		if property, position, ok := strings.Cut(lineValue, ":"); ok {
+		if property, position, ok := cutPosition(lineValue); ok {
 			vals := strings.Split(position, ":")
 			if len(vals) != 2 {
 				return false, fmt.Errorf("property %v has incorrection position: `%v`", property, position)
@@ -139,7 +139,8 @@ func (h *namePosReader) next(c rune) (bool, error) {
 				return false, fmt.Errorf("property %v has incorrection position: `%v`", property, position)
 			}
 
-			h.wb.properties[property] = lab.NewPosition(x, y)
+			name, args := splitPropertyName(property)
+			h.wb.properties = append(h.wb.properties, Property{Name: name, Args: args, Pos: lab.NewPosition(x, y)})
 		}
 
 		h.prefix.Reset()
@@ -150,17 +151,48 @@ func (h *namePosReader) next(c rune) (bool, error) {
 	return false, nil
 }
 
+// cutPosition splits `name[:arg...]: x:y` into the property part and the position part
+func cutPosition(line string) (string, string, bool) {
+	yIdx := strings.LastIndex(line, ":")
+	if yIdx < 0 {
+		return "", "", false
+	}
+
+	xIdx := strings.LastIndex(line[:yIdx], ":")
+	if xIdx < 0 {
+		return line[:yIdx], line[yIdx+1:], true
+	}
+
+	return line[:xIdx], line[xIdx+1:], true
+}
+
+func splitPropertyName(property string) (string, []string) {
+	vals := strings.Split(property, ":")
+	for i := range vals {
+		vals[i] = strings.TrimSpace(vals[i])
+	}
+
+	return vals[0], vals[1:]
+}
+
+// Property is a line below the map table in format `name[:arg...]: x:y`
+type Property struct {
+	Name string
+	Args []string
+	Pos  lab.Position
+}
+
 type WorldBuilder struct {
 	Cf      lab.CellWorldBuilder
 	Factory lab.StringCellFactory
 
 	maxX       int
-	properties map[string]lab.Position
+	properties []Property
 }
 
 func (wb *WorldBuilder) Build(wmap string) (*lab.World, []*lab.Player, error) {
 	cf := &(wb.Cf)
-	wb.properties = map[string]lab.Position{}
+	wb.properties = nil
 	if wb.Factory == nil {
 		wb.Factory = lab.DefaultCellFactory
 	}
@@ -193,23 +225,83 @@ func (wb *WorldBuilder) Build(wmap string) (*lab.World, []*lab.Player, error) {
 	}
 
 	var players []*lab.Player
-	for propName, pos := range wb.properties {
-		if propName == "exit" {
+	var monsters []*lab.Monster
+	for _, prop := range wb.properties {
+		pos := prop.Pos
+		switch prop.Name {
+		case "exit":
 			exitCell, err := wb.Factory.Make("exit", pos)
 			if err != nil {
 				return nil, nil, err
 			}
 			cellMap.Insert(exitCell, pos)
-		} else if propName == "treasure" {
+		case "treasure":
+			value, err := treasureValue(prop)
+			if err != nil {
+				return nil, nil, err
+			}
 			c := cellMap.Get(pos)
-			c.PutItem(&lab.Item{ID: lab.Treasure, Name: "tresure"})
-		} else if propName == "fake_treasure" {
+			c.PutItem(&lab.Item{ID: lab.Treasure, Name: "tresure", Value: value})
+		case "fake_treasure":
 			c := cellMap.Get(pos)
 			c.PutItem(&lab.Item{ID: lab.FakeTreasure, Name: "tresure"})
-		} else {
-			players = append(players, &lab.Player{Name: propName, Pos: pos})
+		case "key":
+			if len(prop.Args) != 1 || prop.Args[0] == "" {
+				return nil, nil, fmt.Errorf("key at %v must have exactly one name, like `key:red: 3:4`", pos)
+			}
+			c := cellMap.Get(pos)
+			c.PutItem(lab.NewKeyItem(prop.Args[0]))
+		case "minotaur":
+			m, err := makeMonster(prop)
+			if err != nil {
+				return nil, nil, err
+			}
+			monsters = append(monsters, m)
+		default:
+			p := lab.NewPlayer(prop.Name, pos)
+			if len(prop.Args) > 0 {
+				p.Team = prop.Args[0]
+			}
+			players = append(players, p)
+		}
+	}
+
+	return &lab.World{Cells: cellMap, Monsters: monsters}, players, nil
+}
+
+// treasureValue reads `treasure[:value]: x:y`
+func treasureValue(prop Property) (int, error) {
+	if len(prop.Args) == 0 {
+		return 1, nil
+	}
+
+	value, err := strconv.Atoi(prop.Args[0])
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("property %v has incorrect value: `%v`", prop.Name, prop.Args[0])
+	}
+
+	return value, nil
+}
+
+// makeMonster reads `minotaur[:mode[:period]]: x:y`
+func makeMonster(prop Property) (*lab.Monster, error) {
+	m := lab.NewMonster(prop.Name, prop.Pos)
+
+	if len(prop.Args) > 0 {
+		mode, err := lab.MonsterModeFromWord(prop.Args[0])
+		if err != nil {
+			return nil, fmt.Errorf("property %v: %w", prop.Name, err)
+		}
+		m.Mode = mode
+	}
+
+	if len(prop.Args) > 1 {
+		period, err := strconv.Atoi(prop.Args[1])
+		if err != nil || period < 1 {
+			return nil, fmt.Errorf("property %v has incorrect period: `%v`", prop.Name, prop.Args[1])
 		}
+		m.Period = period
 	}
 
-	return &lab.World{Cells: cellMap}, players, nil
+	return m, nil
 }
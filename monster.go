@@ -0,0 +1,149 @@
+package labyrinth
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const DefaultMonsterPeriod = 2
+
+type MonsterMode int
+
+const (
+	MonsterWander MonsterMode = iota
+	MonsterChase
+)
+
+func MonsterModeFromWord(word string) (MonsterMode, error) {
+	switch word {
+	case "wander", "":
+		return MonsterWander, nil
+	case "chase":
+		return MonsterChase, nil
+	}
+
+	return MonsterWander, fmt.Errorf("unknown monster mode `%v`", word)
+}
+
+// Monster is a non-player entity living in the World. It is visible for the master only.
+type Monster struct {
+	Name string
+	Pos  Position
+	Mode MonsterMode
+	// Monster moves once every Period turns
+	Period int
+}
+
+func NewMonster(name string, pos Position) *Monster {
+	return &Monster{Name: name, Pos: pos, Period: DefaultMonsterPeriod}
+}
+
+func isMonsterPassable(c Cell) bool {
+	return c != nil && c.Class != CellWall && c.Class != CellDoor && c.Class != CellExit
+}
+
+func (m *Monster) passableDirections(w *World) []MoveDirection {
+	var res []MoveDirection
+	for _, d := range []MoveDirection{North, East, South, West} {
+		if isMonsterPassable(w.Cells.Get(m.Pos.Next(d))) {
+			res = append(res, d)
+		}
+	}
+
+	return res
+}
+
+// chaseDirection returns the first step of the shortest walk to the nearest player
+func (m *Monster) chaseDirection(w *World, players []*Player) MoveDirection {
+	targets := map[Position]struct{}{}
+	for _, p := range players {
+		if !p.Dead {
+			targets[p.Pos] = struct{}{}
+		}
+	}
+
+	firstStep := map[Position]MoveDirection{m.Pos: MoveNil}
+	queue := []Position{m.Pos}
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+
+		if _, ok := targets[pos]; ok && pos != m.Pos {
+			return firstStep[pos]
+		}
+
+		for _, d := range []MoveDirection{North, East, South, West} {
+			next := pos.Next(d)
+			if _, ok := firstStep[next]; ok {
+				continue
+			}
+			if !isMonsterPassable(w.Cells.Get(next)) {
+				continue
+			}
+
+			if pos == m.Pos {
+				firstStep[next] = d
+			} else {
+				firstStep[next] = firstStep[pos]
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return MoveNil
+}
+
+// Step moves the monster by one cell according to its mode
+func (m *Monster) Step(w *World, players []*Player, rnd *rand.Rand) {
+	dir := MoveNil
+	if m.Mode == MonsterChase {
+		dir = m.chaseDirection(w, players)
+	}
+
+	if dir == MoveNil {
+		dirs := m.passableDirections(w)
+		if len(dirs) == 0 {
+			return
+		}
+		dir = dirs[rnd.Intn(len(dirs))]
+	}
+
+	m.Pos = m.Pos.Next(dir)
+}
+
+func (m *Monster) IsAdjacent(pos Position) bool {
+	dx := m.Pos.X - pos.X
+	dy := m.Pos.Y - pos.Y
+	return dx*dx+dy*dy == 1
+}
+
+// Encounter checks if the monster meets the player and returns events for it
+func (m *Monster) Encounter(w *World, p *Player) []Event {
+	if p.Dead {
+		return nil
+	}
+
+	if m.Pos == p.Pos {
+		p.Lives--
+		e := NewEventf2(MonsterAttackEventType, p.Name, m.Name)
+		w.Emmit(e)
+		evs := []Event{e}
+
+		if p.Lives <= 0 {
+			p.Dead = true
+			e2 := NewEventf2(PlayerDiedEventType, p.Name, "")
+			w.Emmit(e2)
+			evs = append(evs, e2)
+		}
+
+		return evs
+	}
+
+	if m.IsAdjacent(p.Pos) {
+		e := NewEventf2(MonsterNearEventType, p.Name, m.Name)
+		w.Emmit(e)
+		return []Event{e}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package labyrinth
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonster_StepChase(t *testing.T) {
+	w := NewWorldFromString(`
+wwwww
+w   w
+w w w
+w   w
+wwwww
+`)
+	m := NewMonster("minotaur", NewPosition(1, 1))
+	m.Mode = MonsterChase
+	p := NewPlayer("alex", NewPosition(1, 3))
+
+	m.Step(w, []*Player{p}, rand.New(rand.NewSource(1)))
+	assert.Equal(t, NewPosition(1, 2), m.Pos)
+
+	evs := m.Encounter(w, p)
+	if assert.Len(t, evs, 1) {
+		assert.Equal(t, EventType(MonsterNearEventType), evs[0].Type)
+	}
+}
+
+func TestMonster_Encounter(t *testing.T) {
+	w := NewWorldFromString("w w")
+	m := NewMonster("minotaur", NewPosition(1, 0))
+	p := NewPlayer("alex", NewPosition(1, 0))
+	p.Lives = 2
+
+	evs := m.Encounter(w, p)
+	assert.Equal(t, 1, p.Lives)
+	assert.False(t, p.Dead)
+	assert.Len(t, evs, 1)
+
+	evs = m.Encounter(w, p)
+	assert.True(t, p.Dead)
+	if assert.Len(t, evs, 2) {
+		assert.Equal(t, EventType(PlayerDiedEventType), evs[1].Type)
+	}
+
+	assert.Empty(t, m.Encounter(w, p))
+}
@@ -1,5 +1,7 @@
 package labyrinth
 
+import "strconv"
+
 type SimpleMoveCommand struct{}
 
 func (c SimpleMoveCommand) Do(w *World, p *Player, direction MoveDirection) []Event {
@@ -35,18 +37,22 @@ type ExitMoveCommand struct{}
 func (c ExitMoveCommand) Do(w *World, p *Player, direction MoveDirection) []Event {
 	se := SimpleMoveCommand{}.Do(w, p, direction)
 
-	if p.Hand == nil {
-		return se
-	}
-
-	if p.Hand.ID == Treasure {
+	if p.Inventory.Find(Treasure) != nil {
 		e := NewEventf2(RevealObjectEventType, p.Name, "genuine")
-		e2 := NewEventf2(WinEventType, p.Name, "")
 		w.Emmit(e)
-		w.Emmit(e2)
 		se = append(se, e)
+
+		points := 0
+		for item := p.Inventory.Find(Treasure); item != nil; item = p.Inventory.Find(Treasure) {
+			p.Inventory.Remove(item)
+			points += item.Value
+		}
+		p.Score += points
+
+		e2 := NewEventf2(TreasureOutEventType, p.Name, strconv.Itoa(points))
+		w.Emmit(e2)
 		se = append(se, e2)
-	} else if p.Hand.ID == FakeTreasure {
+	} else if p.Inventory.Find(FakeTreasure) != nil {
 		e := NewEventf2(RevealObjectEventType, p.Name, "fake")
 		se = append(se, e)
 		w.Emmit(e)
@@ -61,19 +67,30 @@ var moveRouting = map[string]map[string]MoveCommandType{
 		"exit":     &ExitMoveCommand{},
 		"wall":     &WallMoveCommand{},
 		"wormhole": &WormholeMoveCommand{},
+		"door":     &DoorMoveCommand{},
 	},
 	"exit": {
 		"river":    &RiverMoveCommand{},
 		"wall":     &WallMoveCommand{},
 		"wormhole": &WormholeMoveCommand{},
+		"door":     &DoorMoveCommand{},
 	},
 	"river": {
 		"wall":     &WallMoveCommand{},
 		"wormhole": &WormholeMoveCommand{},
+		"door":     &DoorMoveCommand{},
 	},
 	"wormhole": {
 		"wall":  &WormholeMoveCommand{},
 		"river": &RiverMoveCommand{},
+		"door":  &DoorMoveCommand{},
+	},
+	"door": {
+		"river":    &RiverMoveCommand{},
+		"exit":     &ExitMoveCommand{},
+		"wall":     &WallMoveCommand{},
+		"wormhole": &WormholeMoveCommand{},
+		"door":     &DoorMoveCommand{},
 	},
 }
 
@@ -147,8 +164,9 @@ func (rm RiverMoveCommand) interact(w *World, p *Player, recCtxRiverCell *RiverC
 			recEvents = append(recEvents, e)
 			w.Emmit(e)
 
-			if p.Hand != nil {
-				e2 := NewEventf2(LooseObjectEventType, p.Name, p.Hand.Name)
+			// the river takes everything the player carries, the items are gone
+			for _, item := range p.Inventory.TakeAll() {
+				e2 := NewEventf2(LooseObjectEventType, p.Name, item.Name)
 				recEvents = append(recEvents, e2)
 				w.Emmit(e2)
 			}
@@ -0,0 +1,40 @@
+package labyrinth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRiverMoveCommand_ItemsAreLost(t *testing.T) {
+	w := NewWorldFromString(`
+wwwww
+w ↓ w
+w ↓ w
+w ↓ w
+wwwww
+`)
+	p := NewPlayer("alex", NewPosition(1, 1))
+	p.Inventory.Put(&Item{ID: Treasure, Name: "tresure"})
+	p.Inventory.Put(NewKeyItem("red"))
+
+	evs := (&MoveCommand{Direction: East}).Do(w, p)
+
+	assert.Equal(t, NewPosition(2, 3), p.Pos)
+	assert.True(t, p.Inventory.IsEmpty())
+	assert.Len(t, filterEvents(evs, LooseObjectEventType), 2)
+	for _, cell := range w.Cells.All() {
+		assert.Empty(t, cell.Items, "lost items don't turn up anywhere on the map")
+	}
+}
+
+func filterEvents(evs []Event, t EventType) []Event {
+	var res []Event
+	for _, e := range evs {
+		if e.Type == t {
+			res = append(res, e)
+		}
+	}
+
+	return res
+}
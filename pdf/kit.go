@@ -0,0 +1,412 @@
+package pdf
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+const (
+	margin = 40.0
+	// maxCellSize keeps small maps from being drawn with huge cells
+	maxCellSize   = 40.0
+	turnRowHeight = 20.0
+)
+
+var (
+	black      = color.RGBA{A: 255}
+	white      = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	gridGray   = color.RGBA{R: 200, G: 200, B: 200, A: 255}
+	wallGray   = color.RGBA{R: 208, G: 208, B: 208, A: 255}
+	labelGray  = color.RGBA{R: 80, G: 80, B: 80, A: 255}
+	riverBlue  = color.RGBA{R: 166, G: 203, B: 240, A: 255}
+	mouthBlue  = color.RGBA{R: 74, G: 143, B: 216, A: 255}
+	holePurple = color.RGBA{R: 123, G: 63, B: 160, A: 255}
+	doorBrown  = color.RGBA{R: 139, G: 90, B: 43, A: 255}
+	exitGreen  = color.RGBA{R: 60, G: 180, B: 75, A: 255}
+	gold       = color.RGBA{R: 230, G: 184, B: 0, A: 255}
+	fakeGray   = color.RGBA{R: 154, G: 154, B: 154, A: 255}
+	playerRed  = color.RGBA{R: 230, G: 25, B: 75, A: 255}
+)
+
+// grid places cells of a map on a page
+type grid struct {
+	page  *Page
+	x, y  float64
+	size  float64
+	cells *lab.CellMap
+}
+
+// newGrid fits the map into the page below top, leaving bottom points free
+func newGrid(page *Page, cells *lab.CellMap, top float64, bottom float64) grid {
+	labels := 16.0
+	width := PageWidth - 2*margin - labels
+	height := PageHeight - top - bottom - labels
+	size := min(maxCellSize, width/float64(cells.Cols()), height/float64(cells.Rows()))
+
+	return grid{
+		page:  page,
+		x:     margin + labels,
+		y:     top + labels,
+		size:  size,
+		cells: cells,
+	}
+}
+
+func (g grid) origin(pos lab.Position) (float64, float64) {
+	return g.x + float64(pos.X)*g.size, g.y + float64(pos.Y)*g.size
+}
+
+func (g grid) center(pos lab.Position) (float64, float64) {
+	x, y := g.origin(pos)
+	return x + g.size/2, y + g.size/2
+}
+
+func (g grid) bottom() float64 {
+	return g.y + float64(g.cells.Rows())*g.size
+}
+
+// lines draws the empty grid with coordinates of the markdown table header
+func (g grid) lines() {
+	p := g.page
+	p.SetStrokeColor(gridGray)
+	p.SetLineWidth(0.5)
+	for x := 0; x <= g.cells.Cols(); x++ {
+		px := g.x + float64(x)*g.size
+		p.Line(px, g.y, px, g.bottom())
+	}
+	for y := 0; y <= g.cells.Rows(); y++ {
+		py := g.y + float64(y)*g.size
+		p.Line(g.x, py, g.x+float64(g.cells.Cols())*g.size, py)
+	}
+
+	p.SetFillColor(labelGray)
+	fontSize := min(9, g.size/2)
+	for x := 1; x < g.cells.Cols()-1; x++ {
+		cx, _ := g.center(lab.NewPosition(x, 0))
+		p.CenteredText(cx, g.y-8, fontSize, fmt.Sprint(x))
+	}
+	for y := 1; y < g.cells.Rows()-1; y++ {
+		_, cy := g.center(lab.NewPosition(0, y))
+		p.CenteredText(g.x-8, cy, fontSize, fmt.Sprint(y))
+	}
+}
+
+// symbols draw what a cell has, with the size of a cell at x, y
+type symbols struct {
+	page *Page
+	size float64
+}
+
+func (s symbols) arrow(x, y float64, dir lab.MoveDirection) {
+	p := s.page
+	cx, cy := x+s.size/2, y+s.size/2
+	l, head := s.size*0.3, s.size/6
+
+	// arrow pointing east turned to the direction
+	dx, dy := 1.0, 0.0
+	switch dir {
+	case lab.South:
+		dx, dy = 0, 1
+	case lab.West:
+		dx, dy = -1, 0
+	case lab.North:
+		dx, dy = 0, -1
+	}
+	turn := func(ax, ay float64) (float64, float64) {
+		return cx + ax*dx - ay*dy, cy + ax*dy + ay*dx
+	}
+
+	p.SetStrokeColor(mouthBlue)
+	p.SetLineWidth(max(1, s.size/14))
+	x1, y1 := turn(-l, 0)
+	x2, y2 := turn(l, 0)
+	p.Line(x1, y1, x2, y2)
+	hx1, hy1 := turn(l-head, -head)
+	hx2, hy2 := turn(l-head, head)
+	p.Polyline([]float64{hx1, hy1, x2, y2, hx2, hy2})
+}
+
+func (s symbols) text(x, y, dy float64, c color.RGBA, text string) {
+	s.page.SetFillColor(c)
+	s.page.CenteredText(x+s.size/2, y+s.size/2+dy*s.size, s.size/3.5, text)
+}
+
+func (s symbols) river(x, y float64, dir lab.MoveDirection) {
+	s.page.SetFillColor(riverBlue)
+	s.page.Rect(x, y, s.size, s.size, Fill)
+	s.arrow(x, y, dir)
+}
+
+func (s symbols) mouth(x, y float64) {
+	s.page.SetFillColor(mouthBlue)
+	s.page.Rect(x, y, s.size, s.size, Fill)
+	s.text(x, y, 0, white, "RM")
+}
+
+func (s symbols) wormhole(x, y float64, name string) {
+	s.page.SetStrokeColor(holePurple)
+	s.page.SetLineWidth(max(1, s.size/16))
+	s.page.Circle(x+s.size/2, y+s.size/2, s.size*0.4, Stroke)
+	s.text(x, y, 0, holePurple, name)
+}
+
+func (s symbols) door(x, y float64, key string) {
+	s.page.SetFillColor(doorBrown)
+	s.page.Rect(x+s.size/8, y+s.size/8, s.size*3/4, s.size/5, Fill)
+	s.text(x, y, 0.15, doorBrown, key)
+}
+
+func (s symbols) exit(x, y float64) {
+	inset := s.size / 8
+	s.page.SetStrokeColor(exitGreen)
+	s.page.SetLineWidth(max(1, s.size/16))
+	s.page.Rect(x+inset, y+inset, s.size-2*inset, s.size-2*inset, Stroke)
+	s.text(x, y, 0, exitGreen, "exit")
+}
+
+func (s symbols) wall(x, y float64) {
+	s.page.SetFillColor(wallGray)
+	s.page.Rect(x, y, s.size, s.size, Fill)
+}
+
+// item draws the i-th item of a cell in its bottom left corner
+func (s symbols) item(x, y float64, i int, item *lab.Item) {
+	r := s.size / 8
+	cx, cy := x+r+2+float64(i)*(2*r+2), y+s.size-r-2
+
+	p := s.page
+	p.SetStrokeColor(black)
+	p.SetLineWidth(0.5)
+	switch item.ID {
+	case lab.Treasure:
+		p.SetFillColor(gold)
+		p.Polygon([]float64{cx, cy - r, cx + r, cy, cx, cy + r, cx - r, cy}, FillStroke)
+	case lab.FakeTreasure:
+		p.SetFillColor(fakeGray)
+		p.Polygon([]float64{cx, cy - r, cx + r, cy, cx, cy + r, cx - r, cy}, FillStroke)
+	default:
+		p.Circle(cx, cy, r*2/3, Stroke)
+		p.SetFillColor(black)
+		p.Text(cx+r, cy+r/2, s.size/5, item.Tag)
+	}
+}
+
+// start marks where a player starts
+func (s symbols) start(x, y float64, name string) {
+	s.page.SetStrokeColor(playerRed)
+	s.page.SetLineWidth(max(1, s.size/16))
+	s.page.Circle(x+s.size/2, y+s.size/2, s.size*0.3, Stroke)
+	s.text(x, y, -0.25, playerRed, name)
+}
+
+func (s symbols) monster(x, y float64) {
+	s.page.SetFillColor(black)
+	s.page.Circle(x+s.size*0.8, y+s.size*0.2, s.size*0.12, Fill)
+	s.text(x, y, 0.25, black, "M")
+}
+
+// master draws the full map with players, items and monsters
+func master(page *Page, w *lab.World, pls []*lab.Player) {
+	page.SetFillColor(black)
+	page.BoldText(margin, margin+12, 16, "Master's map")
+
+	g := newGrid(page, &w.Cells, margin+24, legendHeight(pls)+margin)
+	s := symbols{page: page, size: g.size}
+
+	for pos, c := range w.Cells.All() {
+		x, y := g.origin(pos)
+		switch c.Class {
+		case lab.CellWall:
+			s.wall(x, y)
+		case lab.CellRiver:
+			if river, ok := c.Custom.(*lab.RiverCell); ok && river.Dir != lab.MoveNil {
+				s.river(x, y, river.Dir)
+			} else {
+				s.mouth(x, y)
+			}
+		case lab.CellWormHole:
+			if hole, ok := c.Custom.(*lab.WormholeCell); ok {
+				s.wormhole(x, y, fmt.Sprintf("%v:%v", hole.Name, hole.Idx))
+			}
+		case lab.CellDoor:
+			if door, ok := c.Custom.(*lab.DoorCell); ok {
+				s.door(x, y, door.Key)
+			}
+		case lab.CellExit:
+			s.exit(x, y)
+		}
+
+		for i, item := range c.Items {
+			s.item(x, y, i, item)
+		}
+	}
+
+	for _, p := range pls {
+		x, y := g.origin(p.Pos)
+		s.start(x, y, initials(p.Name))
+	}
+	for _, m := range w.Monsters {
+		x, y := g.origin(m.Pos)
+		s.monster(x, y)
+	}
+
+	g.lines()
+	walls(g)
+	legend(page, g.bottom()+24, pls)
+}
+
+// initials are the first two letters of a name, they fit into a cell
+func initials(name string) string {
+	r := []rune(name)
+	return string(r[:min(2, len(r))])
+}
+
+// walls draws thick lines where a wall meets a cell which isn't a wall
+func walls(g grid) {
+	g.page.SetStrokeColor(black)
+	g.page.SetLineWidth(max(1.5, g.size/10))
+	for pos, c := range g.cells.All() {
+		if c.Class != lab.CellWall {
+			continue
+		}
+
+		x, y := g.origin(pos)
+		sides := map[lab.MoveDirection][4]float64{
+			lab.North: {x, y, x + g.size, y},
+			lab.East:  {x + g.size, y, x + g.size, y + g.size},
+			lab.South: {x, y + g.size, x + g.size, y + g.size},
+			lab.West:  {x, y, x, y + g.size},
+		}
+		for _, dir := range []lab.MoveDirection{lab.North, lab.East, lab.South, lab.West} {
+			next := pos.Next(dir)
+			if next.X < 0 || next.Y < 0 || next.X >= g.cells.Cols() || next.Y >= g.cells.Rows() {
+				continue
+			}
+			if g.cells.Get(next).Class == lab.CellWall {
+				continue
+			}
+
+			v := sides[dir]
+			g.page.Line(v[0], v[1], v[2], v[3])
+		}
+	}
+}
+
+const legendRows = 4
+
+func legendHeight(pls []*lab.Player) float64 {
+	return 24 + 8 + legendRows*(22+6) + 4 + 12*float64(len(pls))
+}
+
+func legend(page *Page, top float64, pls []*lab.Player) {
+	s := symbols{page: page, size: 22}
+	entries := []struct {
+		draw func(x, y float64)
+		text string
+	}{
+		{s.wall, "wall"},
+		{func(x, y float64) { s.river(x, y, lab.East) }, "river flowing east"},
+		{s.mouth, "river mouth"},
+		{func(x, y float64) { s.wormhole(x, y, "A:0") }, "wormhole A:0, leads to A:1"},
+		{func(x, y float64) { s.door(x, y, "red") }, "door opened by the red key"},
+		{s.exit, "exit"},
+		{func(x, y float64) { s.item(x, y, 0, &lab.Item{ID: lab.Treasure}) }, "treasure"},
+		{func(x, y float64) { s.item(x, y, 0, &lab.Item{ID: lab.FakeTreasure}) }, "fake treasure"},
+		{func(x, y float64) { s.item(x, y, 0, &lab.Item{ID: lab.Key, Tag: "red"}) }, "key"},
+		{func(x, y float64) { s.start(x, y, "al") }, "player start"},
+		{s.monster, "minotaur"},
+	}
+
+	page.SetFillColor(black)
+	page.BoldText(margin, top, 11, "Legend")
+
+	columnWidth := (PageWidth - 2*margin) / 3
+	for i, v := range entries {
+		x := margin + float64(i%3)*columnWidth
+		y := top + 8 + float64(i/3)*(s.size+6)
+
+		page.SetStrokeColor(gridGray)
+		page.SetLineWidth(0.5)
+		page.Rect(x, y, s.size, s.size, Stroke)
+		v.draw(x, y)
+
+		page.SetFillColor(black)
+		page.Text(x+s.size+6, y+s.size/2+3, 9, v.text)
+	}
+
+	y := top + 8 + legendRows*(s.size+6) + 4
+	for _, p := range pls {
+		text := fmt.Sprintf("%v  %v starts at %v", initials(p.Name), p.Name, p.Pos)
+		if p.Team != "" {
+			text += ", team " + p.Team
+		}
+		page.SetFillColor(black)
+		page.Text(margin, y, 9, text)
+		y += 12
+	}
+}
+
+// playerSheet is a blank grid of the size of the labyrinth for a player to draw their map
+func playerSheet(page *Page, cells *lab.CellMap, p *lab.Player) {
+	title := fmt.Sprintf("Map of %v", p.Name)
+	if p.Team != "" {
+		title += fmt.Sprintf(", team %v", p.Team)
+	}
+	page.SetFillColor(black)
+	page.BoldText(margin, margin+12, 16, title)
+
+	newGrid(page, cells, margin+24, margin).lines()
+}
+
+// turnLog is a table to write down moves of every player
+func turnLog(page *Page, pls []*lab.Player) {
+	page.SetFillColor(black)
+	page.BoldText(margin, margin+12, 16, "Turn log")
+
+	top := margin + 24
+	turnWidth := 36.0
+	columnWidth := (PageWidth - 2*margin - turnWidth) / float64(max(1, len(pls)))
+	rows := int((PageHeight - margin - top) / turnRowHeight)
+
+	page.SetFillColor(black)
+	page.BoldText(margin+4, top+turnRowHeight-6, 10, "Turn")
+	for i, p := range pls {
+		page.BoldText(margin+turnWidth+float64(i)*columnWidth+4, top+turnRowHeight-6, 10, p.Name)
+	}
+	for row := 1; row < rows; row++ {
+		page.Text(margin+4, top+float64(row+1)*turnRowHeight-6, 9, fmt.Sprint(row))
+	}
+
+	page.SetStrokeColor(gridGray)
+	page.SetLineWidth(0.5)
+	bottom := top + float64(rows)*turnRowHeight
+	for row := 0; row <= rows; row++ {
+		y := top + float64(row)*turnRowHeight
+		page.Line(margin, y, PageWidth-margin, y)
+	}
+	page.Line(margin, top, margin, bottom)
+	for i := 0; i <= len(pls); i++ {
+		x := margin + turnWidth + float64(i)*columnWidth
+		page.Line(x, top, x, bottom)
+	}
+}
+
+// NewKit makes the kit for the paper game: the master's map with a legend, a blank map for every player
+// and a turn log with the names of the players
+func NewKit(w *lab.World, pls []*lab.Player) *Document {
+	d := New()
+	master(d.AddPage(), w, pls)
+	for _, p := range pls {
+		playerSheet(d.AddPage(), &w.Cells, p)
+	}
+	turnLog(d.AddPage(), pls)
+
+	return d
+}
+
+func WriteKit(out io.Writer, w *lab.World, pls []*lab.Player) error {
+	return NewKit(w, pls).Write(out)
+}
@@ -0,0 +1,56 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+	md "github.com/kepkin/labyrinth/markdown"
+)
+
+const testMap = `| X | 1     | 2  | 3 | 4     |
+|---|-------|----|---|-------|
+| 1 | W:A:0 | R  | R | RM    |
+| 2 |       | w  |   | D:red |
+| 3 |       |    |   | W:A:1 |
+
+exit: 5:3
+treasure: 3:3
+key:red: 1:3
+alex: 1:2
+bob:blue: 3:2
+`
+
+func TestWriteKit(t *testing.T) {
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, pls, err := wb.Build(testMap)
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteKit(buf, w, pls))
+
+	pages := contents(t, buf.Bytes())
+	if !assert.Len(t, pages, 4, "master's map, two player sheets and the turn log") {
+		return
+	}
+
+	assert.Contains(t, pages[0], "(Master's map)")
+	assert.Contains(t, pages[0], "(A:0)")
+	assert.Contains(t, pages[0], "(A:1)")
+	assert.Contains(t, pages[0], "(RM)")
+	assert.Contains(t, pages[0], "(al  alex starts at 1:2)")
+	assert.Contains(t, pages[0], "(bo  bob starts at 3:2, team blue)")
+
+	assert.Contains(t, pages[1], "(Map of alex)")
+	assert.Contains(t, pages[2], "(Map of bob, team blue)")
+	assert.NotContains(t, pages[1], "(A:0)", "player sheets are blank")
+
+	assert.Contains(t, pages[3], "(Turn log)")
+	assert.Contains(t, pages[3], "(alex)")
+	assert.Contains(t, pages[3], "(bob)")
+	assert.Equal(t, 1, strings.Count(pages[3], "(35)"), "rows fill the page")
+	assert.NotContains(t, pages[3], "(36)")
+}
@@ -0,0 +1,253 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+// A4 page size in points
+const (
+	PageWidth  = 595.0
+	PageHeight = 842.0
+)
+
+type DrawMode int
+
+const (
+	Stroke DrawMode = iota
+	Fill
+	FillStroke
+)
+
+func (m DrawMode) operator() string {
+	switch m {
+	case Fill:
+		return "f"
+	case FillStroke:
+		return "B"
+	}
+
+	return "S"
+}
+
+// Document is a PDF with vector graphics and text in Helvetica, which every PDF reader has, so nothing is embedded
+type Document struct {
+	pages []*Page
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+
+	return p
+}
+
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// Page draws with the origin in the top left corner, y grows down like in images
+type Page struct {
+	content bytes.Buffer
+}
+
+func (p *Page) op(format string, args ...any) {
+	fmt.Fprintf(&p.content, format+"\n", args...)
+}
+
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+
+	return s
+}
+
+func rgb(c color.RGBA) string {
+	return fmt.Sprintf("%v %v %v", num(float64(c.R)/255), num(float64(c.G)/255), num(float64(c.B)/255))
+}
+
+func (p *Page) SetStrokeColor(c color.RGBA) {
+	p.op("%v RG", rgb(c))
+}
+
+func (p *Page) SetFillColor(c color.RGBA) {
+	p.op("%v rg", rgb(c))
+}
+
+func (p *Page) SetLineWidth(w float64) {
+	p.op("%v w", num(w))
+}
+
+// SetDash makes lines dashed, zero length makes them solid again
+func (p *Page) SetDash(length float64) {
+	if length <= 0 {
+		p.op("[] 0 d")
+		return
+	}
+	p.op("[%v] 0 d", num(length))
+}
+
+func (p *Page) point(x, y float64) string {
+	return num(x) + " " + num(PageHeight-y)
+}
+
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	p.op("%v m %v l S", p.point(x1, y1), p.point(x2, y2))
+}
+
+func (p *Page) Rect(x, y, w, h float64, mode DrawMode) {
+	p.op("%v %v %v re %v", p.point(x, y+h), num(w), num(h), mode.operator())
+}
+
+// Polygon draws a closed shape through the points, given as x, y pairs
+func (p *Page) Polygon(points []float64, mode DrawMode) {
+	for i := 0; i+1 < len(points); i += 2 {
+		cmd := "l"
+		if i == 0 {
+			cmd = "m"
+		}
+		p.op("%v %v", p.point(points[i], points[i+1]), cmd)
+	}
+	p.op("h %v", mode.operator())
+}
+
+// Polyline draws an open line through the points, given as x, y pairs
+func (p *Page) Polyline(points []float64) {
+	for i := 0; i+1 < len(points); i += 2 {
+		cmd := "l"
+		if i == 0 {
+			cmd = "m"
+		}
+		p.op("%v %v", p.point(points[i], points[i+1]), cmd)
+	}
+	p.op("S")
+}
+
+// Circle is drawn with four Bezier curves
+func (p *Page) Circle(cx, cy, r float64, mode DrawMode) {
+	k := r * 4 * (math.Sqrt2 - 1) / 3
+	p.op("%v m", p.point(cx+r, cy))
+	p.op("%v %v %v c", p.point(cx+r, cy-k), p.point(cx+k, cy-r), p.point(cx, cy-r))
+	p.op("%v %v %v c", p.point(cx-k, cy-r), p.point(cx-r, cy-k), p.point(cx-r, cy))
+	p.op("%v %v %v c", p.point(cx-r, cy+k), p.point(cx-k, cy+r), p.point(cx, cy+r))
+	p.op("%v %v %v c", p.point(cx+k, cy+r), p.point(cx+r, cy+k), p.point(cx+r, cy))
+	p.op("h %v", mode.operator())
+}
+
+// Text writes with the left end of the baseline at x, y. Letters Helvetica doesn't have are written as `?`
+func (p *Page) Text(x, y, size float64, text string) {
+	p.op("BT /F1 %v Tf %v Td (%v) Tj ET", num(size), p.point(x, y), escape(text))
+}
+
+// BoldText is Text in Helvetica-Bold
+func (p *Page) BoldText(x, y, size float64, text string) {
+	p.op("BT /F2 %v Tf %v Td (%v) Tj ET", num(size), p.point(x, y), escape(text))
+}
+
+// CenteredText writes the text centred at x with the middle of lower case letters at y
+func (p *Page) CenteredText(x, y, size float64, text string) {
+	p.Text(x-TextWidth(text, size)/2, y+size*0.35, size, text)
+}
+
+func escape(text string) string {
+	sb := strings.Builder{}
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r < 32 || r > 126:
+			sb.WriteRune('?')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+// helveticaWidths are widths of printable ASCII letters in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// TextWidth is the width of the text written in Helvetica
+func TextWidth(text string, size float64) float64 {
+	res := 0
+	for _, r := range text {
+		if r < 32 || r > 126 {
+			r = '?'
+		}
+		res += helveticaWidths[r-32]
+	}
+
+	return float64(res) * size / 1000
+}
+
+// Write writes the document. Page contents are compressed
+func (d *Document) Write(w io.Writer) error {
+	buf := &bytes.Buffer{}
+	var offsets []int
+
+	object := func(format string, args ...any) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(buf, "%v 0 obj\n", len(offsets))
+		fmt.Fprintf(buf, format, args...)
+		buf.WriteString("\nendobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// objects 1-4 are the catalog, the page tree and fonts, pages and their contents follow
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%v 0 R", 5+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%v] /Count %v >>", strings.Join(kids, " "), len(d.pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, p := range d.pages {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %v %v] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %v 0 R >>",
+			num(PageWidth), num(PageHeight), 6+2*i)
+
+		compressed := &bytes.Buffer{}
+		zw := zlib.NewWriter(compressed)
+		if _, err := zw.Write(p.content.Bytes()); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		object("<< /Length %v /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes())
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %v\n0000000000 65535 f \n", len(offsets)+1)
+	for _, v := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", v)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %v /Root 1 0 R >>\nstartxref\n%v\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,113 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	xrefRe   = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	streamRe = regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+)
+
+// contents checks the cross reference table points to the objects and returns decompressed page contents
+func contents(t *testing.T, src []byte) []string {
+	m := xrefRe.FindSubmatch(src)
+	if !assert.NotNil(t, m, "no startxref") {
+		return nil
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	assert.True(t, bytes.HasPrefix(src[xref:], []byte("xref\n")))
+
+	lines := strings.Split(string(src[xref:]), "\n")
+	for i, line := range lines[3:] {
+		if strings.HasPrefix(line, "trailer") {
+			break
+		}
+		offset, _ := strconv.Atoi(line[:10])
+		assert.True(t, bytes.HasPrefix(src[offset:], []byte(fmt.Sprintf("%v 0 obj\n", i+1))), "object %v", i+1)
+	}
+
+	var res []string
+	for _, loc := range streamRe.FindAllSubmatchIndex(src, -1) {
+		length, _ := strconv.Atoi(string(src[loc[2]:loc[3]]))
+		r, err := zlib.NewReader(bytes.NewReader(src[loc[1] : loc[1]+length]))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		b, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		res = append(res, string(b))
+	}
+
+	return res
+}
+
+func TestDocument_Write(t *testing.T) {
+	d := New()
+	p := d.AddPage()
+	p.SetFillColor(black)
+	p.Rect(10, 20, 30, 40, Fill)
+	p.Text(10, 100, 12, "hello (world)")
+	d.AddPage().Line(0, 0, 10, 10)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, d.Write(buf))
+	src := buf.Bytes()
+
+	assert.True(t, bytes.HasPrefix(src, []byte("%PDF-1.4\n")))
+	assert.Contains(t, string(src), "/Kids [5 0 R 7 0 R] /Count 2")
+	assert.Contains(t, string(src), "/Size 9 /Root 1 0 R")
+
+	pages := contents(t, src)
+	assert.Equal(t, []string{
+		"0 0 0 rg\n10 782 30 40 re f\nBT /F1 12 Tf 10 742 Td (hello \\(world\\)) Tj ET\n",
+		"0 842 m 10 832 l S\n",
+	}, pages)
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"alex", "alex"},
+		{`a\b`, `a\\b`},
+		{"(x)", `\(x\)`},
+		{"Маша", "????"},
+		{"a\tb", "a?b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, escape(tt.text))
+		})
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		text string
+		size float64
+		want float64
+	}{
+		{"", 10, 0},
+		{"i", 10, 2.22},
+		{"Turn", 10, 6.11 + 5.56 + 3.33 + 5.56},
+		{"Ю", 10, 5.56},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.InDelta(t, tt.want, TextWidth(tt.text, tt.size), 0.001)
+		})
+	}
+}
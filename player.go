@@ -6,19 +6,36 @@ const (
 	Nothing HandItem = iota
 	Treasure
 	FakeTreasure
+	Key
 )
 
+const DefaultLives = 3
+
 type Player struct {
 	Name string
 	Pos  Position
+	Team string
 
-	Hand   *Item
-	Lives  int
-	Arrows int
+	Inventory Inventory
+	Lives     int
+	Arrows    int
+	Dead      bool
+	Score     int
 
 	Attrs map[string]string
 
 	Map PlayerMap
+	// Fragments are earlier maps the player can't place relative to the current one
+	Fragments []PlayerMap
+}
+
+func NewPlayer(name string, pos Position) *Player {
+	return &Player{
+		Name:      name,
+		Pos:       pos,
+		Inventory: NewInventory(DefaultInventoryCapacity),
+		Lives:     DefaultLives,
+	}
 }
 
 func (p *Player) SetAttr(attr string, value string) {
@@ -32,7 +49,11 @@ func (p *Player) GetAttr(attr string) string {
 	return p.Attrs[attr]
 }
 
+// NewMap starts a new map from the current position. The old map is kept as a fragment
 func (p *Player) NewMap() {
+	if len(p.Map.KnonwnCells) > 0 {
+		p.Fragments = append(p.Fragments, p.Map)
+	}
 	p.Map = NewPlayerMap(p.Pos)
 	p.Map.Learn(p.Pos)
 }
@@ -0,0 +1,205 @@
+package replay
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"io"
+	"slices"
+	"time"
+
+	lab "github.com/kepkin/labyrinth"
+	limage "github.com/kepkin/labyrinth/image"
+)
+
+const DefaultDelay = 500 * time.Millisecond
+
+type Options struct {
+	// Delay is how long every turn is shown, DefaultDelay is used if it's zero
+	Delay time.Duration
+	// Player is the one whose fog-of-war map is shown, without items. The full map is shown if it's empty
+	Player string
+	// Base renders the map, image.NewCellMapImage is used if it's nil. It has marks unless Player is set, then
+	// only rivers and wormholes the player has learnt are marked
+	Base func(cells *lab.CellMap) (limage.Grid, error)
+}
+
+func (o Options) delay() int {
+	if o.Delay <= 0 {
+		o.Delay = DefaultDelay
+	}
+
+	return max(1, int(o.Delay/(10*time.Millisecond)))
+}
+
+func (o Options) base(cells *lab.CellMap) (limage.Grid, error) {
+	if o.Base == nil {
+		return limage.NewCellMapImage(cells, limage.Options{Marks: o.Player == ""})
+	}
+
+	return o.Base(cells)
+}
+
+// grid is the map rendered once into the palette, so frames only compute the trails
+type grid struct {
+	*image.Paletted
+	cells limage.Grid
+	// view is the map of the player, nil shows all cells
+	view *lab.PlayerMap
+	// marked is the map with all marks, learnt cells of the player's map are taken from it
+	marked *image.Paletted
+	learnt map[lab.Position]struct{}
+}
+
+func (g *grid) CellRect(pos lab.Position) image.Rectangle {
+	return g.cells.CellRect(pos)
+}
+
+func (g *grid) Visible(pos lab.Position) bool {
+	if g.view == nil {
+		return true
+	}
+
+	_, ok := g.view.KnonwnCells[pos]
+	return ok
+}
+
+func (g *grid) At(x, y int) color.Color {
+	size := g.cells.CellRect(lab.Position{}).Size()
+	pos := lab.Position{X: x / size.X, Y: y / size.Y}
+	if !g.Visible(pos) {
+		return color.Black
+	}
+	if _, ok := g.learnt[pos]; ok && g.marked != nil {
+		return g.marked.At(x, y)
+	}
+
+	return g.Paletted.At(x, y)
+}
+
+// quantizer converts images to the palette remembering colours it has already seen
+type quantizer struct {
+	palette color.Palette
+	indexes map[color.RGBA]uint8
+}
+
+func newQuantizer() *quantizer {
+	return &quantizer{palette: palette.Plan9, indexes: map[color.RGBA]uint8{}}
+}
+
+func (q *quantizer) convert(img image.Image) *image.Paletted {
+	b := img.Bounds()
+	res := image.NewPaletted(b, q.palette)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			idx, ok := q.indexes[c]
+			if !ok {
+				idx = uint8(q.palette.Index(c))
+				q.indexes[c] = idx
+			}
+			res.Pix[res.PixOffset(x, y)] = idx
+		}
+	}
+
+	return res
+}
+
+// changed returns the smallest rectangle which has all pixels that differ between the frames
+func changed(prev, next *image.Paletted) image.Rectangle {
+	res := image.Rectangle{}
+	b := next.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if i := next.PixOffset(x, y); prev.Pix[i] != next.Pix[i] {
+				res = res.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	return res
+}
+
+// WriteGIF replays the game and writes an animated GIF with a frame for every turn.
+// Every frame shows the paths players have made so far. Only changed parts of frames are stored, so
+// even long games stay small.
+func WriteGIF(w io.Writer, l *Log, opts Options) error {
+	if opts.Player != "" && !slices.ContainsFunc(l.Seats, func(v Seat) bool { return v.Name == opts.Player }) {
+		return fmt.Errorf("there is no player %v in the game", opts.Player)
+	}
+
+	q := newQuantizer()
+	res := &gif.GIF{}
+
+	var base *grid
+	var prev *image.Paletted
+	err := l.Replay(func(s *lab.Session) error {
+		if base == nil {
+			cells, err := opts.base(&s.World.Cells)
+			if err != nil {
+				return err
+			}
+			base = &grid{Paletted: q.convert(cells), cells: cells}
+
+			if opts.Player != "" && opts.Base == nil {
+				marked, err := limage.NewCellMapImage(&s.World.Cells, limage.Options{Marks: true})
+				if err != nil {
+					return err
+				}
+				base.marked = q.convert(marked)
+			}
+		}
+
+		var names []string
+		if opts.Player != "" {
+			p := s.FindPlayer(opts.Player)
+			if p == nil {
+				// the player has left the game
+				return nil
+			}
+
+			view := s.PlayerView(p)
+			base.view = &view
+			names = append(names, p.Name)
+			for _, v := range s.Teammates(p) {
+				names = append(names, v.Name)
+			}
+			base.learnt = s.LearntCells(names...)
+		}
+
+		var trails *limage.Trails
+		if opts.Player != "" {
+			trails = limage.NewPlayerTrails(base, s, names...)
+		} else {
+			trails = limage.NewTrails(base, s, names...)
+		}
+
+		frame := q.convert(trails)
+		if prev == nil {
+			res.Image = append(res.Image, frame)
+			res.Delay = append(res.Delay, opts.delay())
+			res.Disposal = append(res.Disposal, gif.DisposalNone)
+			prev = frame
+			return nil
+		}
+
+		r := changed(prev, frame)
+		prev = frame
+		if r.Empty() {
+			res.Delay[len(res.Delay)-1] += opts.delay()
+			return nil
+		}
+
+		res.Image = append(res.Image, frame.SubImage(r).(*image.Paletted))
+		res.Delay = append(res.Delay, opts.delay())
+		res.Disposal = append(res.Disposal, gif.DisposalNone)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return gif.EncodeAll(w, res)
+}
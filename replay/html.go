@@ -0,0 +1,190 @@
+package replay
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	lab "github.com/kepkin/labyrinth"
+	limage "github.com/kepkin/labyrinth/image"
+)
+
+//go:embed export.html
+var exportPage string
+
+var exportTemplate = template.Must(template.New("export").Parse(exportPage))
+
+type HTMLOptions struct {
+	// Delay is how long every turn is shown when the timeline plays, DefaultDelay is used if it's zero
+	Delay time.Duration
+	// Base renders the map, image.NewCellMapImage with marks is used if it's nil
+	Base func(cells *lab.CellMap) (limage.Grid, error)
+	// Stringer writes events, lab.DefaultEventStringer is used if it's nil
+	Stringer lab.EventStringer
+}
+
+func (o HTMLOptions) base(cells *lab.CellMap) (limage.Grid, error) {
+	if o.Base == nil {
+		return limage.NewCellMapImage(cells, limage.Options{Marks: true})
+	}
+
+	return o.Base(cells)
+}
+
+// htmlGame is the game as the script of the page sees it
+type htmlGame struct {
+	Cols, Rows int
+	// CellSize is the size of a cell on the map picture
+	CellSize image.Point
+	// Delay is in milliseconds
+	Delay  int
+	Seats  []htmlSeat
+	Frames []htmlFrame
+}
+
+type htmlSeat struct {
+	Name  string
+	Team  string
+	Color string
+}
+
+// htmlFrame is the game after a turn, the first frame is before the first move
+type htmlFrame struct {
+	Turn     int
+	Steps    []htmlStep
+	Players  []htmlPlayer
+	Monsters []lab.Position
+}
+
+type htmlStep struct {
+	Player string
+	Action string
+	Events []string
+}
+
+type htmlPlayer struct {
+	// Seat is the index in htmlGame.Seats
+	Seat int
+	Pos  lab.Position
+	// Path is every cell the player has been through during the turn, river drags and jumps included
+	Path  []lab.Position
+	Lives int
+	Score int
+	Dead  bool
+	Items []string
+	// the map the player sees, with the maps of teammates
+	LeftCorner  lab.Position
+	RightCorner lab.Position
+	Known       []lab.Position
+}
+
+type htmlPage struct {
+	Title string
+	Map   template.URL
+	Game  htmlGame
+}
+
+// WriteHTML replays the game and writes a page which needs nothing else to be opened: the map is embedded as
+// a picture, a slider goes through turns showing where everyone was, what happened and the map of every player
+// as it was at that turn.
+func WriteHTML(w io.Writer, l *Log, opts HTMLOptions) error {
+	stringer := opts.Stringer
+	if stringer == nil {
+		stringer = lab.DefaultEventStringer{}
+	}
+
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = DefaultDelay
+	}
+
+	page := htmlPage{Game: htmlGame{Delay: int(delay / time.Millisecond)}}
+	var names []string
+	for i, v := range l.Seats {
+		c := limage.TrailColors[i%len(limage.TrailColors)]
+		page.Game.Seats = append(page.Game.Seats, htmlSeat{Name: v.Name, Team: v.Team, Color: fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)})
+		names = append(names, v.Name)
+	}
+	page.Title = "Labyrinth: " + strings.Join(names, ", ")
+
+	seen := 0
+	err := l.Replay(func(s *lab.Session) error {
+		if page.Map == "" {
+			cells, err := opts.base(&s.World.Cells)
+			if err != nil {
+				return err
+			}
+
+			data := bytes.NewBuffer(nil)
+			if err := limage.Encode(data, cells, limage.PalettedPNG); err != nil {
+				return err
+			}
+			page.Map = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(data.Bytes()))
+			page.Game.Cols, page.Game.Rows = s.World.Cells.Cols(), s.World.Cells.Rows()
+			page.Game.CellSize = cells.CellRect(lab.Position{}).Size()
+		}
+
+		steps := s.History[seen:]
+		seen = len(s.History)
+
+		frame := htmlFrame{Turn: s.Turn()}
+		for _, st := range steps {
+			hs := htmlStep{Player: st.Player, Action: st.Action}
+			for _, e := range st.Events {
+				hs.Events = append(hs.Events, stringer.ToString(e))
+			}
+			frame.Steps = append(frame.Steps, hs)
+		}
+
+		for _, p := range s.Players {
+			view := s.PlayerView(p)
+			hp := htmlPlayer{
+				Seat:        slices.Index(names, p.Name),
+				Pos:         p.Pos,
+				Lives:       p.Lives,
+				Score:       p.Score,
+				Dead:        p.Dead,
+				LeftCorner:  view.LeftCorner,
+				RightCorner: view.RightCorner,
+			}
+			// like Session.Paths, a step starts where the previous one ended
+			for _, st := range steps {
+				if st.Player == p.Name {
+					hp.Path = append(hp.Path, st.Path(s.World)[1:]...)
+				}
+			}
+			for _, v := range p.Inventory.Items {
+				hp.Items = append(hp.Items, v.Name)
+			}
+			for pos := range view.KnonwnCells {
+				hp.Known = append(hp.Known, pos)
+			}
+			slices.SortFunc(hp.Known, func(a, b lab.Position) int {
+				if a.Y != b.Y {
+					return a.Y - b.Y
+				}
+				return a.X - b.X
+			})
+			frame.Players = append(frame.Players, hp)
+		}
+
+		for _, m := range s.World.Monsters {
+			frame.Monsters = append(frame.Monsters, m.Pos)
+		}
+
+		page.Game.Frames = append(page.Game.Frames, frame)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return exportTemplate.Execute(w, page)
+}
@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+
+	lab "github.com/kepkin/labyrinth"
+	md "github.com/kepkin/labyrinth/markdown"
+)
+
+// Seat is a player as they were when the game started
+type Seat struct {
+	Name  string
+	Team  string `json:",omitempty"`
+	Start lab.Position
+}
+
+// Log is everything needed to play a finished game again: the map, the seed of the session and the steps.
+// Only round robin games can be replayed.
+type Log struct {
+	// Map is the markdown source of the map, players of the map are replaced by Seats
+	Map  string
+	Seed int64
+	// Rule is the name of the victory rule, see lab.NewVictoryRule
+	Rule     string `json:",omitempty"`
+	MaxTurns int    `json:",omitempty"`
+	Seats    []Seat
+	Steps    []lab.Step
+}
+
+// NewLog starts the log of a session, it must be made before the first move.
+// The session must use rand.NewSource(seed) and nothing else may take numbers from it.
+func NewLog(mapSrc string, seed int64, s *lab.Session) *Log {
+	l := &Log{Map: mapSrc, Seed: seed}
+	for _, p := range s.Players {
+		l.Seats = append(l.Seats, Seat{Name: p.Name, Team: p.Team, Start: p.Pos})
+	}
+
+	return l
+}
+
+// Record copies the history of the session into the log
+func (l *Log) Record(s *lab.Session) {
+	l.Steps = slices.Clone(s.History)
+}
+
+func ReadLog(r io.Reader) (*Log, error) {
+	l := &Log{}
+	if err := json.NewDecoder(r).Decode(l); err != nil {
+		return nil, fmt.Errorf("can't read the game log: %w", err)
+	}
+
+	return l, nil
+}
+
+func (l *Log) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(l)
+}
+
+// session builds the session as it was before the first move
+func (l *Log) session() (*lab.Session, error) {
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, _, err := wb.Build(l.Map)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := lab.NewVictoryRule(l.Rule, l.MaxTurns)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &lab.Session{
+		World: w,
+		Rand:  rand.New(rand.NewSource(l.Seed)),
+		Rule:  rule,
+	}
+	for _, v := range l.Seats {
+		s.AddPlayer(v.Name, v.Start)
+		p := s.FindPlayer(v.Name)
+		p.Team = v.Team
+		p.NewMap()
+	}
+
+	return s, nil
+}
+
+func removed(st lab.Step) bool {
+	return slices.ContainsFunc(st.Events, func(e lab.Event) bool {
+		return e.Type == lab.PlayerRemovedEventType && e.Subject == st.Player
+	})
+}
+
+// Replay plays the steps again and calls turn before the first move and after every turn.
+// It fails if the game goes another way than the log says.
+func (l *Log) Replay(turn func(s *lab.Session) error) error {
+	s, err := l.session()
+	if err != nil {
+		return err
+	}
+
+	if err := turn(s); err != nil {
+		return err
+	}
+
+	for i, st := range l.Steps {
+		p := s.GetCurrentPlayer()
+		if s.IsOver() || p == nil || p.Name != st.Player {
+			return fmt.Errorf("step %v: it's not the turn of %v", i+1, st.Player)
+		}
+
+		done := s.Turn()
+		history := len(s.History)
+		s.Do(st.Action)
+		if len(s.History) == history || s.History[history].To != st.To {
+			return fmt.Errorf("step %v: %v didn't get to %v with `%v`, the log doesn't match the map", i+1, st.Player, st.To, st.Action)
+		}
+
+		if removed(st) {
+			s.RemovePlayer(st.Player)
+		}
+
+		if s.Turn() != done {
+			if err := turn(s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/gif"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+	limage "github.com/kepkin/labyrinth/image"
+	md "github.com/kepkin/labyrinth/markdown"
+	"github.com/kepkin/labyrinth/strategy"
+)
+
+const testMap = `| X | 1 | 2 | 3 | 4 |
+|---|---|---|---|---|
+| 1 |   |   |   |   |
+| 2 |   | w | w |   |
+| 3 |   |   |   |   |
+
+exit: 5:3
+treasure: 4:3
+minotaur:wander:1: 3:1
+alex: 1:1
+tanya: 1:3
+`
+
+// plainGrid is a white map with 10px cells
+type plainGrid struct {
+	cells *lab.CellMap
+}
+
+func (g plainGrid) Bounds() image.Rectangle {
+	return image.Rect(0, 0, g.cells.Cols()*10, g.cells.Rows()*10)
+}
+func (g plainGrid) ColorModel() color.Model       { return color.RGBAModel }
+func (g plainGrid) At(x, y int) color.Color       { return color.White }
+func (g plainGrid) Visible(pos lab.Position) bool { return true }
+func (g plainGrid) CellRect(pos lab.Position) image.Rectangle {
+	return image.Rect(pos.X*10, pos.Y*10, pos.X*10+10, pos.Y*10+10)
+}
+
+func plainBase(cells *lab.CellMap) (limage.Grid, error) {
+	return plainGrid{cells: cells}, nil
+}
+
+// playGame plays a game between random bots and returns its log and the final session
+func playGame(t *testing.T, seed int64, turns int) (*Log, *lab.Session) {
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, pls, err := wb.Build(testMap)
+	assert.NoError(t, err)
+
+	s := &lab.Session{World: w, Players: pls, Rand: rand.New(rand.NewSource(seed))}
+	l := NewLog(testMap, seed, s)
+
+	botRand := rand.New(rand.NewSource(seed + 1))
+	var bots []*strategy.Bot
+	for _, p := range pls {
+		p.NewMap()
+		bots = append(bots, strategy.NewBot(p.Name, &strategy.RandomWalker{Rand: botRand}))
+	}
+	strategy.PlayUntil(s, bots, turns, func([]lab.Event) {})
+	l.Record(s)
+
+	return l, s
+}
+
+func TestLog_Replay(t *testing.T) {
+	l, played := playGame(t, 7, 30)
+	assert.NotEmpty(t, l.Steps)
+
+	b := bytes.NewBuffer(nil)
+	assert.NoError(t, l.Write(b))
+	read, err := ReadLog(b)
+	assert.NoError(t, err)
+	assert.Equal(t, l, read)
+
+	var last *lab.Session
+	turns := 0
+	err = read.Replay(func(s *lab.Session) error {
+		last = s
+		turns++
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, played.Turn()+1, turns)
+	assert.Equal(t, played.World.Monsters[0].Pos, last.World.Monsters[0].Pos)
+	for i, p := range played.Players {
+		assert.Equal(t, p.Pos, last.Players[i].Pos)
+		assert.Equal(t, p.Lives, last.Players[i].Lives)
+		assert.Equal(t, p.Map, last.Players[i].Map)
+	}
+}
+
+func TestLog_Replay_Mismatch(t *testing.T) {
+	l, _ := playGame(t, 7, 10)
+	l.Steps[3].To = lab.NewPosition(4, 2)
+
+	err := l.Replay(func(s *lab.Session) error { return nil })
+	assert.ErrorContains(t, err, "step 4")
+
+	l, _ = playGame(t, 7, 10)
+	l.Steps = l.Steps[1:]
+	err = l.Replay(func(s *lab.Session) error { return nil })
+	assert.ErrorContains(t, err, "step 1")
+}
+
+func TestWriteGIF(t *testing.T) {
+	l, played := playGame(t, 3, 12)
+
+	tests := []struct {
+		name   string
+		player string
+	}{
+		{"full map", ""},
+		{"fog", "alex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bytes.NewBuffer(nil)
+			assert.NoError(t, WriteGIF(b, l, Options{Player: tt.player, Base: plainBase}))
+
+			g, err := gif.DecodeAll(b)
+			assert.NoError(t, err)
+			assert.Equal(t, image.Rect(0, 0, 60, 50), g.Image[0].Bounds())
+			assert.LessOrEqual(t, len(g.Image), played.Turn()+1)
+
+			total := 0
+			for _, v := range g.Delay {
+				total += v
+			}
+			assert.Equal(t, (played.Turn()+1)*50, total)
+
+			// the cell next to the exit is never seen by alex at the start
+			corner := g.Image[0].At(45, 35)
+			if tt.player == "" {
+				assert.NotEqual(t, color.RGBA{A: 255}, color.RGBAModel.Convert(corner))
+			} else {
+				assert.Equal(t, color.RGBA{A: 255}, color.RGBAModel.Convert(corner))
+			}
+		})
+	}
+
+	err := WriteGIF(bytes.NewBuffer(nil), l, Options{Player: "nobody", Base: plainBase})
+	assert.Error(t, err)
+}
+
+func TestWriteHTML(t *testing.T) {
+	l, played := playGame(t, 3, 12)
+
+	b := bytes.NewBuffer(nil)
+	assert.NoError(t, WriteHTML(b, l, HTMLOptions{Base: plainBase}))
+	page := b.String()
+
+	assert.Contains(t, page, "<title>Labyrinth: alex, tanya</title>")
+	assert.Contains(t, page, `src="data:image/png;base64,`)
+	assert.NotContains(t, page, "http://")
+	assert.NotContains(t, page, "https://")
+
+	start := strings.Index(page, "const game = ")
+	assert.NotEqual(t, -1, start)
+	src := page[start+len("const game = "):]
+	game := htmlGame{}
+	assert.NoError(t, json.NewDecoder(strings.NewReader(src)).Decode(&game))
+
+	assert.Equal(t, 6, game.Cols)
+	assert.Equal(t, image.Point{X: 10, Y: 10}, game.CellSize)
+	assert.Equal(t, 500, game.Delay)
+	assert.Len(t, game.Frames, played.Turn()+1)
+	assert.Empty(t, game.Frames[0].Steps)
+	assert.Equal(t, lab.DefaultEventStringer{}.ToString(played.History[0].Events[0]), game.Frames[1].Steps[0].Events[0])
+
+	last := game.Frames[len(game.Frames)-1]
+	for i, p := range played.Players {
+		assert.Equal(t, p.Pos, last.Players[i].Pos)
+		assert.Len(t, last.Players[i].Known, len(played.PlayerView(p).KnonwnCells))
+	}
+	assert.Equal(t, played.World.Monsters[0].Pos, last.Monsters[0])
+
+	for i, p := range played.Players {
+		path := []lab.Position{game.Frames[0].Players[i].Pos}
+		for _, f := range game.Frames {
+			path = append(path, f.Players[i].Path...)
+		}
+		assert.Equal(t, played.Paths()[p.Name], path, "paths agree with the trails picture")
+	}
+}
+
+func TestWriteHTML_RiverPath(t *testing.T) {
+	src := `| X | 1 | 2 | 3 | 4  |
+|---|---|---|---|----|
+| 1 |   | R | R | RM |
+| 2 |   |   |   |    |
+
+exit: 5:2
+treasure: 1:2
+alex: 1:1
+`
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, pls, err := wb.Build(src)
+	assert.NoError(t, err)
+
+	s := &lab.Session{World: w, Players: pls, Rand: rand.New(rand.NewSource(1))}
+	l := NewLog(src, 1, s)
+	pls[0].NewMap()
+	s.Do("east")
+	l.Record(s)
+
+	b := bytes.NewBuffer(nil)
+	assert.NoError(t, WriteHTML(b, l, HTMLOptions{Base: plainBase}))
+	page := b.String()
+	game := htmlGame{}
+	assert.NoError(t, json.NewDecoder(strings.NewReader(page[strings.Index(page, "const game = ")+len("const game = "):])).Decode(&game))
+
+	path := s.Paths()["alex"]
+	assert.Greater(t, len(path), 2, "alex is dragged along the river")
+	assert.Equal(t, path[1:], game.Frames[1].Players[0].Path)
+}
@@ -0,0 +1,114 @@
+package labyrinth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type TurnMode int
+
+const (
+	// RoundRobinTurns lets players move one by one
+	RoundRobinTurns TurnMode = iota
+	// SimultaneousTurns collects orders from every player and resolves them together
+	SimultaneousTurns
+)
+
+// Submit stores the order of the player for the current round. A later order replaces the earlier one.
+func (s *Session) Submit(name string, action string) error {
+	if s.over {
+		return fmt.Errorf("game is over")
+	}
+
+	p := s.FindPlayer(name)
+	if p == nil {
+		return fmt.Errorf("no such player %v", name)
+	}
+	if p.Dead {
+		return fmt.Errorf("player %v is dead", name)
+	}
+
+	if s.orders == nil {
+		s.orders = map[string]string{}
+	}
+	s.orders[name] = action
+	delete(s.idleSkips, name)
+
+	return nil
+}
+
+// StartRound starts the round clock. The round may be resolved without missing orders after RoundDeadline.
+func (s *Session) StartRound(now time.Time) {
+	s.roundStarted = now
+}
+
+func (s *Session) RoundExpired(now time.Time) bool {
+	if s.RoundDeadline <= 0 || s.roundStarted.IsZero() {
+		return false
+	}
+
+	return !now.Before(s.roundStarted.Add(s.RoundDeadline))
+}
+
+// RoundReady is true when every alive player has submitted an order
+func (s *Session) RoundReady() bool {
+	for _, p := range s.Players {
+		if _, ok := s.orders[p.Name]; !ok && !p.Dead {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ResolveRound performs all submitted orders and returns the events of the round as a single batch.
+//
+// Orders are resolved in two phases, each in the order players joined the session:
+//   - item actions (pick up, drop, give) run first, so the first player in order gets a contested item;
+//   - moves run next. Players never block each other, so two players entering the same river
+//     are both dragged along the same path.
+//
+// Players without an order stay where they are. Monsters make one step per round and the victory rule is
+// checked once at the end of the round.
+func (s *Session) ResolveRound() []Event {
+	if s.over {
+		return []Event{NewEventf2(ErrorEventType, "", "game is over")}
+	}
+	s.syncPlayers()
+
+	var evs []Event
+	var moved []*Player
+	for _, itemPhase := range []bool{true, false} {
+		for idx, p := range s.Players {
+			order, ok := s.orders[p.Name]
+			if !ok || p.Dead || isItemAction(order) != itemPhase {
+				continue
+			}
+
+			ev, isMove := s.act(idx, order)
+			evs = append(evs, ev...)
+			if isMove {
+				moved = append(moved, p)
+			}
+		}
+	}
+
+	aftermath := s.moveMonsters(moved)
+	aftermath = append(aftermath, s.checkGameOver(append(evs, aftermath...))...)
+
+	e := NewEventf2(RoundEndEventType, "", fmt.Sprint(s.turn))
+	s.World.Emmit(e)
+	aftermath = append(aftermath, e)
+	s.recordAftermath(aftermath)
+	evs = append(evs, aftermath...)
+
+	s.orders = nil
+	s.roundStarted = time.Time{}
+
+	return evs
+}
+
+func isItemAction(action string) bool {
+	return strings.HasPrefix(action, "pick up") || strings.HasPrefix(action, "drop") || strings.HasPrefix(action, "give ")
+}
@@ -0,0 +1,69 @@
+package labyrinth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_ResolveRound(t *testing.T) {
+	s := newTestSession(nil)
+	s.Mode = SimultaneousTurns
+	s.AddPlayer("bob", NewPosition(1, 1))
+
+	assert.NoError(t, s.Submit("bob", "pick up tresure"))
+	assert.NoError(t, s.Submit("alex", "pick up tresure"))
+	assert.False(t, s.RoundReady())
+	assert.Error(t, s.Submit("nobody", "north"))
+	assert.NoError(t, s.Submit("tanya", "east"))
+	assert.True(t, s.RoundReady())
+
+	evs := s.ResolveRound()
+
+	assert.NotNil(t, s.Players[0].Inventory.Find(Treasure), "alex joined first and gets the treasure")
+	assert.True(t, s.Players[2].Inventory.IsEmpty())
+	assert.Equal(t, NewPosition(2, 2), s.Players[1].Pos)
+	assert.Equal(t, EventType(RoundEndEventType), evs[len(evs)-1].Type)
+	assert.Equal(t, 1, s.Turn())
+
+	assert.False(t, s.RoundReady(), "orders are cleared after the round")
+	assert.Equal(t, EventType(ErrorEventType), s.Do("east")[0].Type)
+}
+
+func TestSession_RoundDeadline(t *testing.T) {
+	s := newTestSession(nil)
+	s.Mode = SimultaneousTurns
+	s.RoundDeadline = time.Minute
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.False(t, s.RoundExpired(now))
+
+	s.StartRound(now)
+	assert.False(t, s.RoundExpired(now.Add(30*time.Second)))
+	assert.True(t, s.RoundExpired(now.Add(time.Minute)))
+
+	assert.NoError(t, s.Submit("alex", "east"))
+	s.ResolveRound()
+	assert.Equal(t, NewPosition(2, 1), s.Players[0].Pos)
+	assert.Equal(t, NewPosition(1, 2), s.Players[1].Pos)
+}
+
+func TestSession_AutoSkipRound(t *testing.T) {
+	s := newTestSession(nil)
+	s.Mode = SimultaneousTurns
+	s.MaxIdleSkips = 2
+
+	assert.NoError(t, s.Submit("alex", "east"))
+	evs := s.AutoSkip()
+	assert.Equal(t, NewPosition(2, 1), s.Players[0].Pos)
+	assert.Equal(t, 1, s.Turn(), "the round is resolved")
+	assert.Contains(t, evs, NewEventf2(SkipEventType, "tanya", ""))
+	assert.NotContains(t, evs, NewEventf2(ErrorEventType, "", "submit orders for the round instead"))
+
+	assert.NoError(t, s.Submit("alex", "west"))
+	evs = s.AutoSkip()
+	assert.Contains(t, evs, NewEventf2(PlayerRemovedEventType, "tanya", ""))
+	assert.Len(t, s.Players, 1)
+	assert.Equal(t, "alex", s.Players[0].Name, "players who sent orders are never removed")
+}
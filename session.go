@@ -2,8 +2,10 @@ package labyrinth
 
 import (
 	"fmt"
+	"math/rand"
 	"slices"
 	"strings"
+	"time"
 )
 
 func NewCycledInt(max int64, initialValue int64) CycledInt {
@@ -40,11 +42,87 @@ type Session struct {
 	Players              []*Player
 	PlayerHasUncertainty []bool
 
+	// Rand drives every random decision of the session. Set it to replay a game with the same seed
+	Rand *rand.Rand
+	// Rule decides when the game is over. FirstOutWithTreasure is used by default
+	Rule VictoryRule
+	Mode TurnMode
+	// RoundDeadline limits how long a round waits for orders in SimultaneousTurns mode
+	RoundDeadline time.Duration
+	// MaxIdleSkips is the number of automatic skips in a row after which a player is removed. Zero means never.
+	MaxIdleSkips int
+	// History has every action which was done, in order
+	History []Step
+
 	currentPlayer CycledInt
+	turn          int
+	over          bool
+
+	orders       map[string]string
+	roundStarted time.Time
+	idleSkips    map[string]int
+}
+
+// syncPlayers keeps per player state in line with Players which may be set directly
+func (s *Session) syncPlayers() {
+	for len(s.PlayerHasUncertainty) < len(s.Players) {
+		s.PlayerHasUncertainty = append(s.PlayerHasUncertainty, false)
+	}
+}
+
+func (s *Session) rule() VictoryRule {
+	if s.Rule == nil {
+		s.Rule = &FirstOutWithTreasure{}
+	}
+
+	return s.Rule
+}
+
+func (s *Session) IsOver() bool {
+	return s.over
+}
+
+func (s *Session) Scoreboard() Scoreboard {
+	return s.rule().Rank(s)
+}
+
+// checkGameOver finishes the game if the victory rule says so
+func (s *Session) checkGameOver(evs []Event) []Event {
+	if !s.rule().IsOver(s, evs) {
+		return nil
+	}
+	s.over = true
+
+	scoreboard := s.Scoreboard()
+	var res []Event
+	// dead players don't win, even if they got more points
+	if len(scoreboard) > 0 && scoreboard[0].Alive {
+		e := NewEventf2(WinEventType, scoreboard[0].Player, "")
+		s.World.Emmit(e)
+		res = append(res, e)
+	}
+
+	e := NewEventf2(GameOverEventType, "", scoreboard.String())
+	s.World.Emmit(e)
+
+	return append(res, e)
+}
+
+func (s *Session) rand() *rand.Rand {
+	if s.Rand == nil {
+		s.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	return s.Rand
+}
+
+// Turn returns the number of moves made in the session
+func (s *Session) Turn() int {
+	return s.turn
 }
 
 func (s *Session) AddPlayer(name string, p Position) {
-	s.Players = append(s.Players, &Player{Name: name, Pos: p})
+	s.Players = append(s.Players, NewPlayer(name, p))
 	s.PlayerHasUncertainty = append(s.PlayerHasUncertainty, false)
 	s.currentPlayer.SetMax(int64(len(s.Players)))
 }
@@ -57,24 +135,34 @@ func (s *Session) GetCurrentPlayer() *Player {
 }
 
 func (s *Session) RemovePlayer(name string) {
+	s.syncPlayers()
 	for i, v := range s.Players {
 		if v.Name != name {
 			continue
 		}
 
-		if s.currentPlayer.Current() >= int64(i) && i != 0 {
+		if s.currentPlayer.Current() > int64(i) {
 			s.currentPlayer.value--
 		}
 		s.Players = slices.Delete(s.Players, i, i+1)
+		s.PlayerHasUncertainty = slices.Delete(s.PlayerHasUncertainty, i, i+1)
 		s.currentPlayer.SetMax(int64(len(s.Players)))
+		if s.currentPlayer.Current() >= int64(len(s.Players)) {
+			s.currentPlayer.value = 0
+		}
+
+		return
 	}
 }
 
 func (s *Session) HookPreMove() {
-	res := s.Players[s.currentPlayer.Current()]
-	if s.PlayerHasUncertainty[s.currentPlayer.Current()] {
-		res.NewMap()
-		s.PlayerHasUncertainty[s.currentPlayer.Current()] = false
+	s.hookPreMove(int(s.currentPlayer.Current()))
+}
+
+func (s *Session) hookPreMove(idx int) {
+	if s.PlayerHasUncertainty[idx] {
+		s.Players[idx].NewMap()
+		s.PlayerHasUncertainty[idx] = false
 	}
 }
 
@@ -82,52 +170,151 @@ func (s *Session) SetCurrentPlayerUncertainty(uncertainty bool) {
 	s.PlayerHasUncertainty[s.currentPlayer.Current()] = uncertainty
 }
 
+// HasUncertainty tells if the player was moved by a river or a wormhole and doesn't know where they are yet
+func (s *Session) HasUncertainty(p *Player) bool {
+	s.syncPlayers()
+	idx := slices.Index(s.Players, p)
+
+	return idx >= 0 && s.PlayerHasUncertainty[idx]
+}
+
 // Returns possible actions
 func (s *Session) GetCurrentPlayerPossibleActions() []string {
-	res := []string{"north", "south", "west", "east"}
+	return s.PossibleActions(s.Players[s.currentPlayer.Current()])
+}
 
-	p := s.Players[s.currentPlayer.Current()]
+// PossibleActions returns actions of the player, in SimultaneousTurns mode they are offered to every player
+func (s *Session) PossibleActions(p *Player) []string {
+	res := []string{"north", "south", "west", "east"}
 
 	c := s.World.Cells.Get(p.Pos)
 
-	if p.Hand != nil {
-		res = append(res, fmt.Sprintf("drop  %v", p.Hand.Name))
-	} else {
+	for _, v := range p.Inventory.Items {
+		res = append(res, fmt.Sprintf("drop %v", v.Name))
+	}
+
+	if !p.Inventory.IsFull() {
 		for _, v := range c.Items {
 			res = append(res, fmt.Sprintf("pick up %v", v.Name))
 		}
 	}
 
+	for _, mate := range s.Teammates(p) {
+		if mate.Pos != p.Pos || mate.Inventory.IsFull() {
+			continue
+		}
+		for _, v := range p.Inventory.Items {
+			res = append(res, fmt.Sprintf("give %v to %v", v.Name, mate.Name))
+		}
+	}
+
 	return res
 }
 
 func (s *Session) Do(text string) []Event {
+	if s.over {
+		return []Event{NewEventf2(ErrorEventType, "", "game is over")}
+	}
+
+	if s.Mode == SimultaneousTurns {
+		return []Event{NewEventf2(ErrorEventType, "", "submit orders for the round instead")}
+	}
+
+	s.syncPlayers()
+	p := s.GetCurrentPlayer()
+	delete(s.idleSkips, p.Name)
+	ev, moved := s.act(int(s.currentPlayer.Current()), text)
+	if !moved {
+		return ev
+	}
+
+	aftermath := s.moveMonsters([]*Player{p})
+	aftermath = append(aftermath, s.checkGameOver(append(ev, aftermath...))...)
+	s.recordAftermath(aftermath)
+	s.nextPlayer()
+
+	return append(ev, aftermath...)
+}
+
+// act performs the action of the player with index idx and records it in History. It returns true if the action was a move
+func (s *Session) act(idx int, text string) ([]Event, bool) {
+	p := s.Players[idx]
+	from := p.Pos
+
+	ev, moved := s.doAction(idx, text)
+	if !slices.ContainsFunc(ev, func(e Event) bool { return e.Type == ErrorEventType }) {
+		s.History = append(s.History, Step{Turn: s.turn, Player: p.Name, Action: text, From: from, To: p.Pos, Events: slices.Clone(ev)})
+	}
+
+	return ev, moved
+}
+
+// recordAftermath adds events which followed the last action to its step
+func (s *Session) recordAftermath(evs []Event) {
+	if len(s.History) > 0 {
+		last := &s.History[len(s.History)-1]
+		last.Events = append(last.Events, evs...)
+	}
+}
+
+func (s *Session) doAction(idx int, text string) ([]Event, bool) {
+	p := s.Players[idx]
+
 	if strings.HasPrefix(text, "pick up") {
 		object := strings.TrimPrefix(text, "pick up ")
 
-		p := s.GetCurrentPlayer()
+		if p.Inventory.IsFull() {
+			return []Event{NewEventf2(ErrorEventType, p.Name, "inventory is full")}, false
+		}
+
 		c := s.World.Cells.Get(p.Pos)
 		item := c.TakeItem(object)
-		p.Hand = item
-		s.World.Emmit(NewEventf2(PickObjectEventType, p.Name, item.Name))
+		if item == nil {
+			return []Event{NewEventf2(ErrorEventType, p.Name, "nothing to pick up")}, false
+		}
+		p.Inventory.Put(item)
+		e := NewEventf2(PickObjectEventType, p.Name, item.Name)
+		s.World.Emmit(e)
 
-		return nil
+		return []Event{e}, false
 	}
 
-	p := s.GetCurrentPlayer()
+	if strings.HasPrefix(text, "give ") {
+		return s.give(p, text), false
+	}
+
+	if text == "skip" {
+		e := NewEventf2(SkipEventType, p.Name, "")
+		s.World.Emmit(e)
+		return []Event{e}, true
+	}
+
+	if strings.HasPrefix(text, "drop") {
+		object := strings.TrimSpace(strings.TrimPrefix(text, "drop"))
+
+		item := p.Inventory.Take(object)
+		if item == nil {
+			return []Event{NewEventf2(ErrorEventType, p.Name, "nothing to drop")}, false
+		}
+		s.World.Cells.Get(p.Pos).PutItem(item)
+		e := NewEventf2(DropObjectEventType, p.Name, item.Name)
+		s.World.Emmit(e)
+
+		return []Event{e}, false
+	}
 
 	dir, err := MoveDirectionFromWord(text)
 	if err != nil {
-		return []Event{NewEventf2(ErrorEventType, p.Name, "impossible move")}
+		return []Event{NewEventf2(ErrorEventType, p.Name, "impossible move")}, false
 	}
 	s.World.Emmit(NewEventf2(MoveEventType, p.Name, dir.String()))
-	nextPlayerPos := s.GetCurrentPlayer().Pos.Next(dir)
+	nextPlayerPos := p.Pos.Next(dir)
 
 	mc := MoveCommand{
 		Direction: dir,
 	}
 
-	s.HookPreMove()
+	s.hookPreMove(idx)
 	ev := mc.Do(s.World, p)
 	uncertainty := false
 	for _, event := range ev {
@@ -136,10 +323,107 @@ func (s *Session) Do(text string) []Event {
 			break
 		}
 	}
-	s.SetCurrentPlayerUncertainty(uncertainty)
+	s.PlayerHasUncertainty[idx] = uncertainty
 
 	p.Map.Learn(nextPlayerPos)
-	s.currentPlayer.Next()
+	if p.MergeFragments(&s.World.Cells) {
+		e := NewEventf2(MapMergedEventType, p.Name, "")
+		s.World.Emmit(e)
+		ev = append(ev, e)
+	}
+
+	return ev, true
+}
+
+// AutoSkip skips the turn of the current player because of inactivity.
+// After MaxIdleSkips automatic skips in a row the player is removed from the session.
+func (s *Session) AutoSkip() []Event {
+	if s.over || len(s.Players) == 0 {
+		return nil
+	}
+
+	if s.Mode == SimultaneousTurns {
+		return s.autoSkipRound()
+	}
+
+	p := s.GetCurrentPlayer()
+	skips := s.idleSkips[p.Name] + 1
+
+	evs := s.Do("skip")
+	return append(evs, s.countIdleSkip(p.Name, skips)...)
+}
 
-	return ev
+// autoSkipRound submits skip orders for the players who haven't sent theirs and resolves the round
+func (s *Session) autoSkipRound() []Event {
+	idle := map[string]int{}
+	for _, p := range s.Players {
+		if _, ok := s.orders[p.Name]; ok || p.Dead {
+			continue
+		}
+
+		idle[p.Name] = s.idleSkips[p.Name] + 1
+		_ = s.Submit(p.Name, "skip")
+	}
+
+	evs := s.ResolveRound()
+	for _, p := range slices.Clone(s.Players) {
+		if skips, ok := idle[p.Name]; ok {
+			evs = append(evs, s.countIdleSkip(p.Name, skips)...)
+		}
+	}
+
+	return evs
+}
+
+// countIdleSkip remembers the automatic skips of the player in a row and removes the player after MaxIdleSkips
+func (s *Session) countIdleSkip(name string, skips int) []Event {
+	if s.idleSkips == nil {
+		s.idleSkips = map[string]int{}
+	}
+	s.idleSkips[name] = skips
+
+	if s.MaxIdleSkips <= 0 || skips < s.MaxIdleSkips {
+		return nil
+	}
+
+	s.RemovePlayer(name)
+	delete(s.idleSkips, name)
+
+	e := NewEventf2(PlayerRemovedEventType, name, "")
+	s.World.Emmit(e)
+	s.recordAftermath([]Event{e})
+
+	return []Event{e}
+}
+
+// moveMonsters makes one monster turn. Monsters that don't move this turn check only the acting players
+func (s *Session) moveMonsters(acting []*Player) []Event {
+	s.turn++
+
+	var evs []Event
+	for _, m := range s.World.Monsters {
+		moved := false
+		if m.Period <= 1 || s.turn%m.Period == 0 {
+			m.Step(s.World, s.Players, s.rand())
+			moved = true
+		}
+
+		for _, p := range s.Players {
+			if moved || slices.Contains(acting, p) {
+				evs = append(evs, m.Encounter(s.World, p)...)
+			}
+		}
+	}
+
+	return evs
+}
+
+// nextPlayer passes the turn to the next alive player
+func (s *Session) nextPlayer() {
+	for range s.Players {
+		s.currentPlayer.Next()
+		if !s.GetCurrentPlayer().Dead {
+			return
+		}
+	}
 }
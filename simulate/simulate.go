@@ -0,0 +1,192 @@
+package simulate
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	lab "github.com/kepkin/labyrinth"
+	"github.com/kepkin/labyrinth/strategy"
+)
+
+// DefaultMaxTurns stops games where bots wander forever
+const DefaultMaxTurns = 1000
+
+// Loader makes a fresh world for every game, e.g. by parsing the map again.
+// Calls are serialized because cell factories keep state while a map is built.
+type Loader func() (*lab.World, []*lab.Player, error)
+
+type Config struct {
+	// Strategies maps player names to strategy names. Players not listed play the explorer strategy
+	Strategies map[string]string
+	// Rule is the name of the victory rule, see lab.NewVictoryRule
+	Rule string
+	// Games are played with seeds FirstSeed, FirstSeed+1, ...
+	FirstSeed int64
+	Games     int
+	// MaxTurns ends a game without a winner. DefaultMaxTurns is used if it's zero
+	MaxTurns int
+	// Workers is the number of games played in parallel. Zero means one per CPU
+	Workers int
+}
+
+type PlayerResult struct {
+	Player        string
+	Start         lab.Position
+	Won           bool
+	Dead          bool
+	Points        int
+	TreasuresLost int
+}
+
+type GameResult struct {
+	Seed     int64
+	Turns    int
+	Finished bool
+	Players  []PlayerResult
+}
+
+// TreasuresLost counts genuine treasures washed away by rivers in the game
+func (r GameResult) TreasuresLost() int {
+	res := 0
+	for _, p := range r.Players {
+		res += p.TreasuresLost
+	}
+
+	return res
+}
+
+func (c Config) maxTurns() int {
+	if c.MaxTurns <= 0 {
+		return DefaultMaxTurns
+	}
+
+	return c.MaxTurns
+}
+
+func genuineTreasures(p *lab.Player) int {
+	res := 0
+	for _, v := range p.Inventory.Items {
+		if v.ID == lab.Treasure {
+			res++
+		}
+	}
+
+	return res
+}
+
+// Play plays one game where every seat is a bot
+func Play(load Loader, cfg Config, seed int64) (GameResult, error) {
+	w, pls, err := load()
+	if err != nil {
+		return GameResult{}, err
+	}
+
+	rule, err := lab.NewVictoryRule(cfg.Rule, cfg.maxTurns())
+	if err != nil {
+		return GameResult{}, err
+	}
+
+	s := &lab.Session{
+		World:   w,
+		Players: pls,
+		Rand:    rand.New(rand.NewSource(seed)),
+		Rule:    rule,
+	}
+
+	res := GameResult{Seed: seed}
+	carried := map[string]int{}
+	var bots []*strategy.Bot
+	for _, p := range pls {
+		st, err := strategy.New(cfg.Strategies[p.Name], s.Rand)
+		if err != nil {
+			return GameResult{}, err
+		}
+		bots = append(bots, strategy.NewBot(p.Name, st))
+		res.Players = append(res.Players, PlayerResult{Player: p.Name, Start: p.Pos})
+		p.NewMap()
+	}
+
+	strategy.PlayUntil(s, bots, cfg.maxTurns(), func(evs []lab.Event) {
+		for _, e := range evs {
+			if e.Type != lab.LooseObjectEventType {
+				continue
+			}
+
+			p := s.FindPlayer(e.Subject)
+			if lost := carried[p.Name] - genuineTreasures(p); lost > 0 {
+				res.Players[playerIndex(res.Players, p.Name)].TreasuresLost += lost
+			}
+		}
+
+		for _, p := range s.Players {
+			carried[p.Name] = genuineTreasures(p)
+		}
+	})
+
+	res.Turns = s.Turn()
+	res.Finished = s.IsOver()
+	for _, v := range s.Scoreboard() {
+		r := &res.Players[playerIndex(res.Players, v.Player)]
+		r.Won = res.Finished && v.Place == 1
+		r.Dead = !v.Alive
+		r.Points = v.Points
+	}
+
+	return res, nil
+}
+
+func playerIndex(pls []PlayerResult, name string) int {
+	for i, v := range pls {
+		if v.Player == name {
+			return i
+		}
+	}
+
+	panic(fmt.Sprintf("unknown player %v", name))
+}
+
+// Run plays cfg.Games games in parallel. Results are ordered by seed
+func Run(load Loader, cfg Config) ([]GameResult, error) {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	res := make([]GameResult, cfg.Games)
+	errs := make([]error, cfg.Games)
+	jobs := make(chan int)
+
+	mu := sync.Mutex{}
+	serialLoad := func() (*lab.World, []*lab.Player, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return load()
+	}
+
+	wg := sync.WaitGroup{}
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				res[i], errs[i] = Play(serialLoad, cfg, cfg.FirstSeed+int64(i))
+			}
+		}()
+	}
+
+	for i := range cfg.Games {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
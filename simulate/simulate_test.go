@@ -0,0 +1,68 @@
+package simulate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+func smallMap() (*lab.World, []*lab.Player, error) {
+	w := lab.NewWorldFromString(`
+wwwwww
+w    w
+w ww e
+w    w
+wwwwww
+`)
+	w.Cells.Get(lab.NewPosition(4, 3)).PutItem(&lab.Item{ID: lab.Treasure, Name: "tresure", Value: 1})
+
+	return w, []*lab.Player{lab.NewPlayer("alex", lab.NewPosition(1, 1)), lab.NewPlayer("tanya", lab.NewPosition(1, 3))}, nil
+}
+
+func TestRun_IsReproducible(t *testing.T) {
+	cfg := Config{Games: 8, FirstSeed: 10, Workers: 3, Strategies: map[string]string{"tanya": "random"}}
+
+	a, err := Run(smallMap, cfg)
+	assert.NoError(t, err)
+	b, err := Run(smallMap, Config{Games: 8, FirstSeed: 10, Workers: 1, Strategies: cfg.Strategies})
+	assert.NoError(t, err)
+
+	assert.Equal(t, a, b)
+	for i, g := range a {
+		assert.Equal(t, int64(10+i), g.Seed)
+		assert.True(t, g.Finished)
+	}
+}
+
+func TestRun_UnknownStrategy(t *testing.T) {
+	_, err := Run(smallMap, Config{Games: 2, Strategies: map[string]string{"alex": "cheater"}})
+	assert.Error(t, err)
+}
+
+func TestNewStats(t *testing.T) {
+	games := []GameResult{
+		{Turns: 10, Finished: true, Players: []PlayerResult{
+			{Player: "alex", Won: true, Points: 2},
+			{Player: "tanya", TreasuresLost: 1},
+		}},
+		{Turns: 20, Finished: true, Players: []PlayerResult{
+			{Player: "alex"},
+			{Player: "tanya", Won: true, Points: 1},
+		}},
+		{Turns: 30, Players: []PlayerResult{
+			{Player: "alex", Dead: true},
+			{Player: "tanya"},
+		}},
+	}
+
+	st := NewStats(Config{Strategies: map[string]string{"tanya": "random"}}, games)
+
+	assert.Equal(t, 3, st.Games)
+	assert.Equal(t, 2, st.Finished)
+	assert.Equal(t, 20.0, st.AvgTurns)
+	assert.InDelta(t, 1.0/3, st.TreasureLossRate, 0.001)
+	assert.Equal(t, PlayerStats{Player: "alex", Strategy: "explorer", Wins: 1, WinRate: 1.0 / 3, Deaths: 1, AvgPoints: 2.0 / 3}, st.Players[0])
+	assert.Equal(t, PlayerStats{Player: "tanya", Strategy: "random", Wins: 1, WinRate: 1.0 / 3, AvgPoints: 1.0 / 3, TreasuresLost: 1}, st.Players[1])
+}
@@ -0,0 +1,134 @@
+package simulate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+type PlayerStats struct {
+	Player   string       `json:"player"`
+	Start    lab.Position `json:"-"`
+	Strategy string       `json:"strategy"`
+	Wins     int          `json:"wins"`
+	WinRate  float64      `json:"win_rate"`
+	Deaths   int          `json:"deaths"`
+	// AvgPoints is the average of treasure points carried out
+	AvgPoints     float64 `json:"avg_points"`
+	TreasuresLost int     `json:"treasures_lost"`
+}
+
+type Stats struct {
+	Games    int     `json:"games"`
+	Finished int     `json:"finished"`
+	AvgTurns float64 `json:"avg_turns"`
+	// TreasureLossRate is the share of games where at least one genuine treasure was lost to a river
+	TreasureLossRate float64       `json:"treasure_loss_rate"`
+	Players          []PlayerStats `json:"players"`
+}
+
+func NewStats(cfg Config, games []GameResult) Stats {
+	res := Stats{Games: len(games)}
+	if len(games) == 0 {
+		return res
+	}
+
+	for _, v := range games[0].Players {
+		st := cfg.Strategies[v.Player]
+		if st == "" {
+			st = "explorer"
+		}
+		res.Players = append(res.Players, PlayerStats{Player: v.Player, Start: v.Start, Strategy: st})
+	}
+
+	turns, withLoss := 0, 0
+	points := make([]int, len(res.Players))
+	for _, g := range games {
+		turns += g.Turns
+		if g.Finished {
+			res.Finished++
+		}
+		if g.TreasuresLost() > 0 {
+			withLoss++
+		}
+
+		for i, v := range g.Players {
+			ps := &res.Players[i]
+			if v.Won {
+				ps.Wins++
+			}
+			if v.Dead {
+				ps.Deaths++
+			}
+			ps.TreasuresLost += v.TreasuresLost
+			points[i] += v.Points
+		}
+	}
+
+	n := float64(len(games))
+	res.AvgTurns = float64(turns) / n
+	res.TreasureLossRate = float64(withLoss) / n
+	for i := range res.Players {
+		res.Players[i].WinRate = float64(res.Players[i].Wins) / n
+		res.Players[i].AvgPoints = float64(points[i]) / n
+	}
+
+	return res
+}
+
+func (s Stats) WriteJSON(w io.Writer) error {
+	type playerJSON struct {
+		PlayerStats
+		Start string `json:"start"`
+	}
+	v := struct {
+		Stats
+		Players []playerJSON `json:"players"`
+	}{Stats: s}
+	for _, p := range s.Players {
+		v.Players = append(v.Players, playerJSON{PlayerStats: p, Start: p.Start.String()})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// WriteCSV writes a row per start position. Game wide numbers are repeated in every row
+func (s Stats) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{
+		"player", "start", "strategy", "games", "wins", "win_rate", "deaths", "avg_points", "treasures_lost",
+		"finished", "avg_turns", "treasure_loss_rate",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range s.Players {
+		err = cw.Write([]string{
+			p.Player,
+			p.Start.String(),
+			p.Strategy,
+			strconv.Itoa(s.Games),
+			strconv.Itoa(p.Wins),
+			fmt.Sprintf("%.3f", p.WinRate),
+			strconv.Itoa(p.Deaths),
+			fmt.Sprintf("%.2f", p.AvgPoints),
+			strconv.Itoa(p.TreasuresLost),
+			strconv.Itoa(s.Finished),
+			fmt.Sprintf("%.1f", s.AvgTurns),
+			fmt.Sprintf("%.3f", s.TreasureLossRate),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
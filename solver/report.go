@@ -0,0 +1,115 @@
+package solver
+
+import (
+	"slices"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+type StartReport struct {
+	Player   string
+	Start    lab.Position
+	Solvable bool
+	// ToTreasure and ToExit are numbers of moves on the best path
+	ToTreasure int
+	ToExit     int
+	// RiverDrags and Teleports count moves on the best path that end in a river or a wormhole
+	RiverDrags int
+	Teleports  int
+	Reachable  int
+	// Advantage compares the start with the average solvable one: positive means fewer moves than average
+	Advantage float64
+}
+
+func (r StartReport) Moves() int {
+	return r.ToTreasure + r.ToExit
+}
+
+type Report struct {
+	Starts []StartReport
+	// Fairness is 1 when every start needs the same number of moves and goes down to 0 as they differ.
+	// A map with an unsolvable start has zero fairness.
+	Fairness float64
+}
+
+func countTraps(steps []Step) (int, int) {
+	drags, teleports := 0, 0
+	for _, v := range steps {
+		isDrag := slices.ContainsFunc(v.Events, func(e lab.Event) bool { return e.Type == lab.RiverDragEventType })
+		isTeleport := slices.ContainsFunc(v.Events, func(e lab.Event) bool { return e.Type == lab.TeleportEventType })
+		if isDrag {
+			drags++
+		}
+		if isTeleport {
+			teleports++
+		}
+	}
+
+	return drags, teleports
+}
+
+// NewReport solves the map for every player start and compares the starts
+func NewReport(w *lab.World, players []*lab.Player) Report {
+	s := New(w)
+
+	res := Report{}
+	for _, p := range players {
+		r := StartReport{Player: p.Name, Start: p.Pos, Reachable: len(s.Reachable(p.Pos))}
+
+		sol, err := s.Solve(p.Pos)
+		if err == nil {
+			r.Solvable = true
+			r.ToTreasure = countMoves(sol.ToTreasure)
+			r.ToExit = countMoves(sol.ToExit)
+			r.RiverDrags, r.Teleports = countTraps(sol.Steps())
+		}
+
+		res.Starts = append(res.Starts, r)
+	}
+
+	res.Fairness = fairness(res.Starts)
+
+	// unsolvable starts have no moves, they would make the average start look faster than it is
+	total, solvable := 0, 0
+	for _, r := range res.Starts {
+		if r.Solvable {
+			total += r.Moves()
+			solvable++
+		}
+	}
+	if solvable == 0 {
+		return res
+	}
+
+	mean := float64(total) / float64(solvable)
+	for i := range res.Starts {
+		if res.Starts[i].Solvable && mean > 0 {
+			res.Starts[i].Advantage = (mean - float64(res.Starts[i].Moves())) / mean
+		}
+	}
+
+	return res
+}
+
+func fairness(starts []StartReport) float64 {
+	if len(starts) == 0 {
+		return 0
+	}
+
+	shortest, longest := -1, 0
+	for _, r := range starts {
+		if !r.Solvable {
+			return 0
+		}
+		if shortest < 0 || r.Moves() < shortest {
+			shortest = r.Moves()
+		}
+		longest = max(longest, r.Moves())
+	}
+
+	if longest == 0 {
+		return 1
+	}
+
+	return float64(shortest) / float64(longest)
+}
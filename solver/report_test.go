@@ -0,0 +1,54 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+func TestNewReport(t *testing.T) {
+	w := lab.NewWorld([][]string{
+		{"w", "w", "w", "w", "w", "w"},
+		{"w", "", "", "", "", "e"},
+		{"w", "→", "→", "RM", "", "w"},
+		{"w", "w", "w", "w", "w", "w"},
+	})
+	w.Cells.Get(lab.NewPosition(4, 2)).PutItem(&lab.Item{ID: lab.Treasure, Name: "tresure"})
+
+	r := NewReport(w, []*lab.Player{
+		lab.NewPlayer("alex", lab.NewPosition(4, 1)),
+		lab.NewPlayer("tanya", lab.NewPosition(1, 1)),
+	})
+
+	assert.Equal(t, StartReport{
+		Player: "alex", Start: lab.NewPosition(4, 1), Solvable: true,
+		ToTreasure: 1, ToExit: 2, Reachable: 9, Advantage: 0.5 / 3.5,
+	}, r.Starts[0])
+
+	assert.Equal(t, 2, r.Starts[1].ToTreasure)
+	assert.Equal(t, 1, r.Starts[1].RiverDrags)
+	assert.InDelta(t, 0.75, r.Fairness, 0.001)
+}
+
+func TestNewReport_UnsolvableStart(t *testing.T) {
+	w := lab.NewWorld([][]string{
+		{"w", "w", "w", "w", "w", "w"},
+		{"w", "", "", "", "", "e"},
+		{"w", "w", "w", "w", "w", "w"},
+		{"w", "", "w", "w", "w", "w"},
+		{"w", "w", "w", "w", "w", "w"},
+	})
+	w.Cells.Get(lab.NewPosition(3, 1)).PutItem(&lab.Item{ID: lab.Treasure, Name: "tresure"})
+
+	r := NewReport(w, []*lab.Player{
+		lab.NewPlayer("alex", lab.NewPosition(1, 1)),
+		lab.NewPlayer("tanya", lab.NewPosition(1, 3)),
+	})
+
+	assert.True(t, r.Starts[0].Solvable)
+	assert.False(t, r.Starts[1].Solvable)
+	assert.Equal(t, 0.0, r.Starts[0].Advantage, "the only solvable start is the average one")
+	assert.Equal(t, 0.0, r.Starts[1].Advantage)
+}
@@ -0,0 +1,305 @@
+package solver
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+var ErrUnsolvable = errors.New("treasure can't be carried out from this position")
+
+var directions = []lab.MoveDirection{lab.North, lab.East, lab.South, lab.West}
+
+// Step is an action for Session.Do and what it leads to
+type Step struct {
+	Action string
+	Pos    lab.Position
+	// Events are the events the action produces, they show river drags and teleports on the way
+	Events []lab.Event
+}
+
+type Solution struct {
+	Start      lab.Position
+	ToTreasure []Step
+	ToExit     []Step
+}
+
+// Moves is the number of turns the solution takes. Picking up items doesn't take a turn
+func (s Solution) Moves() int {
+	return countMoves(s.Steps())
+}
+
+func countMoves(steps []Step) int {
+	res := 0
+	for _, v := range steps {
+		if !strings.HasPrefix(v.Action, "pick up") {
+			res++
+		}
+	}
+
+	return res
+}
+
+func (s Solution) Steps() []Step {
+	return slices.Concat(s.ToTreasure, s.ToExit)
+}
+
+type state struct {
+	pos      lab.Position
+	carrying bool
+	// sorted names of carried keys joined by comma
+	keys string
+}
+
+func (st state) keyList() []string {
+	if st.keys == "" {
+		return nil
+	}
+
+	return strings.Split(st.keys, ",")
+}
+
+func (st state) items() int {
+	res := len(st.keyList())
+	if st.carrying {
+		res++
+	}
+
+	return res
+}
+
+func (st state) withKey(tag string) state {
+	keys := st.keyList()
+	if slices.Contains(keys, tag) {
+		return st
+	}
+	keys = append(keys, tag)
+	slices.Sort(keys)
+	st.keys = strings.Join(keys, ",")
+
+	return st
+}
+
+type transition struct {
+	from state
+	step Step
+}
+
+// Solver searches the shortest way to pick up a genuine treasure and carry it out.
+// It plays by the game's own MoveCommand rules on a private copy of the world.
+//
+// The solver doesn't model monsters and never lets the player step into a river while carrying
+// something, because the river washes the items away.
+type Solver struct {
+	world    *lab.World
+	capacity int
+}
+
+func New(w *lab.World) *Solver {
+	return &Solver{
+		world:    &lab.World{Cells: w.Cells.Clone()},
+		capacity: lab.DefaultInventoryCapacity,
+	}
+}
+
+// move simulates a move and reports the new state. ok is false if the move is pointless or forbidden
+func (s *Solver) move(st state, dir lab.MoveDirection) (state, Step, bool) {
+	p := lab.NewPlayer("", st.pos)
+	p.Inventory.Capacity = 0
+	for _, tag := range st.keyList() {
+		p.Inventory.Put(lab.NewKeyItem(tag))
+	}
+	if st.carrying {
+		p.Inventory.Put(&lab.Item{ID: lab.Treasure, Name: "tresure"})
+	}
+	carried := slices.Clone(p.Inventory.Items)
+
+	evs := (&lab.MoveCommand{Direction: dir}).Do(s.world, p)
+	// a river puts washed away items on the cell, they must not stay in the world
+	landing := s.world.Cells.Get(p.Pos)
+	landing.Items = slices.DeleteFunc(landing.Items, func(e *lab.Item) bool {
+		return slices.Contains(carried, e)
+	})
+
+	next := st
+	next.pos = p.Pos
+
+	for _, e := range evs {
+		switch e.Type {
+		case lab.LooseObjectEventType, lab.ErrorEventType:
+			return st, Step{}, false
+		case lab.TreasureOutEventType:
+			next.carrying = false
+		}
+	}
+
+	return next, Step{Action: dir.String(), Pos: p.Pos, Events: evs}, true
+}
+
+// pickUps lists items the player may pick up in the state
+func (s *Solver) pickUps(st state) []transition {
+	if st.items() >= s.capacity {
+		return nil
+	}
+
+	var res []transition
+	for _, item := range s.world.Cells.Get(st.pos).Items {
+		next := st
+		switch item.ID {
+		case lab.Treasure:
+			if st.carrying {
+				continue
+			}
+			next.carrying = true
+		case lab.Key:
+			next = st.withKey(item.Tag)
+		default:
+			continue
+		}
+
+		if next != st {
+			res = append(res, transition{from: st, step: Step{Action: "pick up " + item.Name, Pos: st.pos}})
+		}
+	}
+
+	return res
+}
+
+func (s *Solver) pickUpTarget(t transition) state {
+	st := t.from
+	for _, item := range s.world.Cells.Get(st.pos).Items {
+		if "pick up "+item.Name != t.step.Action {
+			continue
+		}
+		if item.ID == lab.Treasure {
+			st.carrying = true
+		} else if item.ID == lab.Key {
+			st = st.withKey(item.Tag)
+		}
+		break
+	}
+
+	return st
+}
+
+// search runs 0-1 BFS: moves cost a turn, picking up is free. It stops when done returns true for a transition
+func (s *Solver) search(start state, done func(from state, step Step) bool) ([]Step, bool) {
+	prev := map[state]transition{}
+	dist := map[state]int{start: 0}
+	deque := []state{start}
+
+	var path func(st state) []Step
+	path = func(st state) []Step {
+		var res []Step
+		for st != start {
+			t := prev[st]
+			res = append(res, t.step)
+			st = t.from
+		}
+		slices.Reverse(res)
+		return res
+	}
+
+	for len(deque) > 0 {
+		st := deque[0]
+		deque = deque[1:]
+
+		for _, t := range s.pickUps(st) {
+			next := s.pickUpTarget(t)
+			if d, ok := dist[next]; ok && d <= dist[st] {
+				continue
+			}
+			dist[next] = dist[st]
+			prev[next] = t
+			deque = append([]state{next}, deque...)
+		}
+
+		for _, dir := range directions {
+			next, step, ok := s.move(st, dir)
+			if !ok {
+				continue
+			}
+
+			if done(st, step) {
+				return append(path(st), step), true
+			}
+
+			if d, ok := dist[next]; ok && d <= dist[st]+1 {
+				continue
+			}
+			dist[next] = dist[st] + 1
+			prev[next] = transition{from: st, step: step}
+			deque = append(deque, next)
+		}
+	}
+
+	return nil, false
+}
+
+// Solve finds the shortest sequence of actions from start to the exit with a genuine treasure
+func (s *Solver) Solve(start lab.Position) (Solution, error) {
+	steps, ok := s.search(state{pos: start}, func(from state, step Step) bool {
+		for _, e := range step.Events {
+			if e.Type == lab.TreasureOutEventType {
+				return true
+			}
+		}
+		return false
+	})
+	if !ok {
+		return Solution{Start: start}, ErrUnsolvable
+	}
+
+	res := Solution{Start: start}
+	for i, v := range steps {
+		if s.isTreasure(v) {
+			res.ToTreasure = steps[:i+1]
+			res.ToExit = steps[i+1:]
+			break
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Solver) isTreasure(step Step) bool {
+	for _, item := range s.world.Cells.Get(step.Pos).Items {
+		if "pick up "+item.Name == step.Action && item.ID == lab.Treasure {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reachable returns the least number of moves to every cell reachable from start without picking anything up
+func (s *Solver) Reachable(start lab.Position) map[lab.Position]int {
+	res := map[lab.Position]int{start: 0}
+	queue := []lab.Position{start}
+
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+
+		for _, dir := range directions {
+			next, _, ok := s.move(state{pos: pos}, dir)
+			if !ok {
+				continue
+			}
+			if _, ok := res[next.pos]; ok {
+				continue
+			}
+			res[next.pos] = res[pos] + 1
+			queue = append(queue, next.pos)
+		}
+	}
+
+	return res
+}
+
+// Solve is a shortcut for New(w).Solve(start)
+func Solve(w *lab.World, start lab.Position) (Solution, error) {
+	return New(w).Solve(start)
+}
@@ -0,0 +1,60 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+func actions(steps []Step) []string {
+	var res []string
+	for _, v := range steps {
+		res = append(res, v.Action)
+	}
+
+	return res
+}
+
+func TestSolve(t *testing.T) {
+	w := lab.NewWorld([][]string{
+		{"w", "w", "w", "w", "w", "w"},
+		{"w", "", "", "", "", "e"},
+		{"w", "", "w", "w", "D:red", "w"},
+		{"w", "", "", "", "", "w"},
+		{"w", "w", "w", "w", "w", "w"},
+	})
+	w.Cells.Get(lab.NewPosition(4, 3)).PutItem(&lab.Item{ID: lab.Treasure, Name: "tresure"})
+	w.Cells.Get(lab.NewPosition(1, 3)).PutItem(lab.NewKeyItem("red"))
+
+	sol, err := Solve(w, lab.NewPosition(1, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"south", "south", "pick up red key", "east", "east", "east", "pick up tresure"}, actions(sol.ToTreasure))
+	assert.Equal(t, []string{"north", "north", "east"}, actions(sol.ToExit))
+	assert.Equal(t, 8, sol.Moves())
+
+	w.Cells.Get(lab.NewPosition(1, 3)).TakeItem("red key")
+	sol, err = Solve(w, lab.NewPosition(1, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"south", "south", "east", "east", "east", "pick up tresure"}, actions(sol.ToTreasure))
+	assert.Equal(t, []string{"west", "west", "west", "north", "north", "east", "east", "east", "east"}, actions(sol.ToExit))
+	assert.Equal(t, 14, sol.Moves())
+}
+
+func TestSolve_RiverWashesTreasure(t *testing.T) {
+	w := lab.NewWorld([][]string{
+		{"w", "w", "w", "w", "w"},
+		{"w", "", "↓", "", "e"},
+		{"w", "w", "RM", "w", "w"},
+		{"w", "w", "w", "w", "w"},
+	})
+	w.Cells.Get(lab.NewPosition(1, 1)).PutItem(&lab.Item{ID: lab.Treasure, Name: "tresure"})
+
+	_, err := Solve(w, lab.NewPosition(1, 1))
+	assert.ErrorIs(t, err, ErrUnsolvable)
+
+	reachable := New(w).Reachable(lab.NewPosition(1, 1))
+	assert.Equal(t, 1, reachable[lab.NewPosition(2, 2)])
+	assert.Equal(t, 4, reachable[lab.NewPosition(4, 1)])
+}
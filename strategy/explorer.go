@@ -0,0 +1,190 @@
+package strategy
+
+import (
+	"math/rand"
+	"strings"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+var directions = []lab.MoveDirection{lab.North, lab.East, lab.South, lab.West}
+
+// Explorer keeps its own map from what it hears, relative to the place where it last lost its bearings.
+// It walks to the nearest unexplored side of a known cell, picks up treasures and carries them
+// to the exit once it knows where the exit is. Rivers and wormholes make it start a new map.
+type Explorer struct {
+	Rand *rand.Rand
+
+	pos   lab.Position
+	cells map[lab.Position]string
+	exit  *lab.Position
+	// fakes are places where a revealed fake treasure was left
+	fakes map[lab.Position]struct{}
+
+	carrying   bool
+	lastAction string
+}
+
+func NewExplorer(rnd *rand.Rand) *Explorer {
+	e := &Explorer{Rand: rnd}
+	e.reset("")
+	return e
+}
+
+func (e *Explorer) reset(class string) {
+	e.pos = lab.Position{}
+	e.cells = map[lab.Position]string{e.pos: class}
+	e.exit = nil
+	e.fakes = map[lab.Position]struct{}{}
+}
+
+// observe updates the map with the outcome of the last action
+func (e *Explorer) observe(view View) {
+	dir, err := lab.MoveDirectionFromWord(e.lastAction)
+	isMove := err == nil
+
+	lostBearings := false
+	learned := ""
+	for _, ev := range view.Events {
+		if ev.Subject != view.Player {
+			continue
+		}
+
+		switch ev.Type {
+		case lab.RiverDragEventType, lab.TeleportEventType:
+			lostBearings = true
+		case lab.LearnCellEventType:
+			learned = ev.Value
+		case lab.LockedDoorEventType:
+			learned = lab.CellWall
+		case lab.PickObjectEventType:
+			if strings.HasPrefix(ev.Value, "tresure") {
+				e.carrying = true
+			}
+		case lab.LooseObjectEventType, lab.TreasureOutEventType:
+			e.carrying = false
+		case lab.RevealObjectEventType:
+			if ev.Value == "fake" {
+				e.fakes[e.pos] = struct{}{}
+			}
+		}
+	}
+
+	if lostBearings {
+		e.reset(learned)
+		return
+	}
+
+	if !isMove || learned == "" {
+		return
+	}
+
+	next := e.pos.Next(dir)
+	e.cells[next] = learned
+	if learned == lab.CellWall {
+		return
+	}
+
+	e.pos = next
+	if learned == lab.CellExit {
+		exit := next
+		e.exit = &exit
+	}
+}
+
+func isSafe(class string) bool {
+	return class == lab.CellEarth || class == lab.CellExit || class == lab.CellDoor || class == lab.CellRiverMouth
+}
+
+// pathTo returns the first step of the shortest walk over safe known cells to a cell matching goal
+func (e *Explorer) pathTo(goal func(p lab.Position) (lab.MoveDirection, bool)) (lab.MoveDirection, bool) {
+	first := map[lab.Position]lab.MoveDirection{e.pos: lab.MoveNil}
+	queue := []lab.Position{e.pos}
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		if d, ok := goal(p); ok {
+			if p == e.pos {
+				return d, true
+			}
+			return first[p], true
+		}
+
+		for _, d := range directions {
+			next := p.Next(d)
+			if _, ok := first[next]; ok {
+				continue
+			}
+			if !isSafe(e.cells[next]) {
+				continue
+			}
+
+			if p == e.pos {
+				first[next] = d
+			} else {
+				first[next] = first[p]
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return lab.MoveNil, false
+}
+
+func (e *Explorer) decide(view View) string {
+	if _, fake := e.fakes[e.pos]; fake {
+		for _, a := range view.Actions {
+			if strings.HasPrefix(a, "drop tresure") {
+				e.carrying = false
+				return a
+			}
+		}
+	} else if !e.carrying {
+		for _, a := range view.Actions {
+			if strings.HasPrefix(a, "pick up") {
+				return a
+			}
+		}
+	}
+
+	if e.carrying && e.exit != nil {
+		exit := *e.exit
+		d, ok := e.pathTo(func(p lab.Position) (lab.MoveDirection, bool) {
+			for _, d := range directions {
+				if p.Next(d) == exit {
+					return d, true
+				}
+			}
+			return lab.MoveNil, false
+		})
+		if ok && d != lab.MoveNil {
+			return d.String()
+		}
+	}
+
+	d, ok := e.pathTo(func(p lab.Position) (lab.MoveDirection, bool) {
+		var unknown []lab.MoveDirection
+		for _, d := range directions {
+			if _, known := e.cells[p.Next(d)]; !known {
+				unknown = append(unknown, d)
+			}
+		}
+		if len(unknown) == 0 {
+			return lab.MoveNil, false
+		}
+		return unknown[e.Rand.Intn(len(unknown))], true
+	})
+	if ok && d != lab.MoveNil {
+		return d.String()
+	}
+
+	return moves[e.Rand.Intn(len(moves))]
+}
+
+func (e *Explorer) Next(view View) string {
+	e.observe(view)
+	e.lastAction = e.decide(view)
+	return e.lastAction
+}
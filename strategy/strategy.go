@@ -0,0 +1,184 @@
+package strategy
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// View is everything a human player would know when it's their turn
+type View struct {
+	Player string
+	// Events heard since the previous turn of the player, including the outcome of the player's own move
+	Events []lab.Event
+	Map    *lab.PlayerMap
+	// Actions are the actions offered to the player, like the keyboard in the Telegram bot
+	Actions []string
+}
+
+// Strategy chooses an action for Session.Do
+type Strategy interface {
+	Next(view View) string
+}
+
+func New(name string, rnd *rand.Rand) (Strategy, error) {
+	switch name {
+	case "random":
+		return &RandomWalker{Rand: rnd}, nil
+	case "explorer", "":
+		return NewExplorer(rnd), nil
+	}
+
+	return nil, fmt.Errorf("unknown strategy `%v`", name)
+}
+
+// Bot is a seat in a session played by a strategy
+type Bot struct {
+	Name     string
+	Strategy Strategy
+
+	heard []lab.Event
+}
+
+func NewBot(name string, strategy Strategy) *Bot {
+	return &Bot{Name: name, Strategy: strategy}
+}
+
+func (b *Bot) Hear(evs ...lab.Event) {
+	b.heard = append(b.heard, evs...)
+}
+
+// Play makes the move of the bot. The session's current player must be the bot
+func (b *Bot) Play(s *lab.Session) []lab.Event {
+	p := s.GetCurrentPlayer()
+
+	action := b.Strategy.Next(View{
+		Player:  b.Name,
+		Events:  b.heard,
+		Map:     &p.Map,
+		Actions: s.GetCurrentPlayerPossibleActions(),
+	})
+	b.heard = nil
+
+	return s.Do(action)
+}
+
+// Order submits the order of the bot for the round of a SimultaneousTurns session
+func (b *Bot) Order(s *lab.Session) error {
+	p := s.FindPlayer(b.Name)
+	if p == nil {
+		return fmt.Errorf("no such player %v", b.Name)
+	}
+
+	action := b.Strategy.Next(View{
+		Player:  b.Name,
+		Events:  b.heard,
+		Map:     &p.Map,
+		Actions: s.PossibleActions(p),
+	})
+	b.heard = nil
+
+	return s.Submit(b.Name, action)
+}
+
+// SubmitOrders makes every alive bot submit its order for the round, so the round waits only for humans
+func SubmitOrders(s *lab.Session, bots []*Bot) {
+	for _, b := range bots {
+		if p := s.FindPlayer(b.Name); p != nil && !p.Dead {
+			_ = b.Order(s)
+		}
+	}
+}
+
+func findBot(bots []*Bot, name string) *Bot {
+	for _, b := range bots {
+		if b.Name == name {
+			return b
+		}
+	}
+
+	return nil
+}
+
+// MaxBotActions is how many actions a bot may take in one turn before it is made to skip
+const MaxBotActions = 8
+
+// HasHumans tells if any alive player of the session isn't played by a bot
+func HasHumans(s *lab.Session, bots []*Bot) bool {
+	for _, p := range s.Players {
+		if !p.Dead && findBot(bots, p.Name) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PlayBots plays while the current player is a bot and returns all events of these turns.
+// It stops when no human is left, a game of bots alone would never give the turn back.
+// Every bot hears the events, humans should be told about them by the caller.
+func PlayBots(s *lab.Session, bots []*Bot) []lab.Event {
+	var res []lab.Event
+	play(s, bots, func() bool { return HasHumans(s, bots) }, func(evs []lab.Event) {
+		res = append(res, evs...)
+	})
+
+	return res
+}
+
+// PlayUntil plays while the current player is a bot and the session has made less than maxTurns moves
+// (0 means no limit). Events of every action are passed to observe.
+func PlayUntil(s *lab.Session, bots []*Bot, maxTurns int, observe func(evs []lab.Event)) {
+	play(s, bots, func() bool { return maxTurns <= 0 || s.Turn() < maxTurns }, observe)
+}
+
+func play(s *lab.Session, bots []*Bot, more func() bool, observe func(evs []lab.Event)) {
+	actions, turn := 0, s.Turn()
+	for !s.IsOver() && len(s.Players) > 0 && more() {
+		b := findBot(bots, s.GetCurrentPlayer().Name)
+		if b == nil {
+			break
+		}
+
+		if s.Turn() != turn {
+			actions, turn = 0, s.Turn()
+		}
+		actions++
+
+		var evs []lab.Event
+		if actions > MaxBotActions {
+			b.heard = nil
+			evs = s.Do("skip")
+		} else {
+			evs = b.Play(s)
+		}
+		Broadcast(bots, evs...)
+		observe(evs)
+	}
+}
+
+// Broadcast lets every bot hear the events
+func Broadcast(bots []*Bot, evs ...lab.Event) {
+	for _, b := range bots {
+		b.Hear(evs...)
+	}
+}
+
+var moves = []string{"north", "east", "south", "west"}
+
+// RandomWalker picks up whatever it finds and otherwise walks in a random direction
+type RandomWalker struct {
+	Rand *rand.Rand
+}
+
+func (r *RandomWalker) Next(view View) string {
+	for _, a := range view.Actions {
+		if strings.HasPrefix(a, "pick up") {
+			return a
+		}
+	}
+
+	return moves[r.Rand.Intn(len(moves))]
+}
@@ -0,0 +1,107 @@
+package strategy
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+func TestExplorer_WinsSmallMap(t *testing.T) {
+	w := lab.NewWorldFromString(`
+wwwwww
+w    w
+w ww e
+w    w
+wwwwww
+`)
+	w.Cells.Get(lab.NewPosition(4, 3)).PutItem(&lab.Item{ID: lab.Treasure, Name: "tresure", Value: 1})
+
+	s := &lab.Session{World: w, Rand: rand.New(rand.NewSource(1))}
+	s.AddPlayer("bot", lab.NewPosition(1, 1))
+	s.Players[0].NewMap()
+
+	bots := []*Bot{NewBot("bot", NewExplorer(rand.New(rand.NewSource(1))))}
+	PlayUntil(s, bots, 100, func([]lab.Event) {})
+
+	assert.True(t, s.IsOver())
+	assert.Equal(t, 1, s.Players[0].Score)
+}
+
+func TestRandomWalker_PicksUp(t *testing.T) {
+	r := &RandomWalker{Rand: rand.New(rand.NewSource(1))}
+
+	assert.Equal(t, "pick up tresure", r.Next(View{Actions: []string{"north", "south", "pick up tresure"}}))
+	assert.Contains(t, moves, r.Next(View{Actions: []string{"north", "south"}}))
+}
+
+func TestExplorer_KnowsWalls(t *testing.T) {
+	e := NewExplorer(rand.New(rand.NewSource(1)))
+	e.lastAction = "north"
+	e.observe(View{Player: "bot", Events: []lab.Event{lab.NewEventf2(lab.LearnCellEventType, "bot", lab.CellWall)}})
+	assert.Equal(t, lab.Position{}, e.pos)
+
+	for i := 0; i < 10; i++ {
+		assert.NotEqual(t, "north", e.decide(View{}), "explorer doesn't bump into a known wall")
+	}
+}
+
+type stubborn struct{}
+
+func (stubborn) Next(view View) string {
+	return "pick up nothing"
+}
+
+func TestPlayBotsSkipsStuckBot(t *testing.T) {
+	w := lab.NewWorldFromString(`
+www
+w w
+www
+`)
+	s := &lab.Session{World: w, Rand: rand.New(rand.NewSource(1))}
+	s.AddPlayer("bot", lab.NewPosition(1, 1))
+	s.AddPlayer("human", lab.NewPosition(1, 1))
+
+	evs := PlayBots(s, []*Bot{NewBot("bot", stubborn{})})
+
+	assert.Equal(t, "human", s.GetCurrentPlayer().Name)
+	assert.Equal(t, lab.EventType(lab.SkipEventType), evs[len(evs)-1].Type)
+}
+
+func TestSubmitOrders(t *testing.T) {
+	w := lab.NewWorldFromString(`
+wwww
+w  w
+wwww
+`)
+	s := &lab.Session{World: w, Rand: rand.New(rand.NewSource(1)), Mode: lab.SimultaneousTurns}
+	s.AddPlayer("bot", lab.NewPosition(1, 1))
+	s.AddPlayer("human", lab.NewPosition(2, 1))
+
+	SubmitOrders(s, []*Bot{NewBot("bot", stubborn{})})
+	assert.False(t, s.RoundReady(), "the human hasn't sent an order")
+
+	assert.NoError(t, s.Submit("human", "skip"))
+	assert.True(t, s.RoundReady())
+}
+
+func TestPlayBotsStopsWithoutHumans(t *testing.T) {
+	w := lab.NewWorldFromString(`
+wwww
+w  w
+wwww
+`)
+	s := &lab.Session{World: w, Rand: rand.New(rand.NewSource(1))}
+	s.AddPlayer("bot", lab.NewPosition(1, 1))
+	s.AddPlayer("human", lab.NewPosition(2, 1))
+	bots := []*Bot{NewBot("bot", NewExplorer(rand.New(rand.NewSource(1))))}
+
+	assert.True(t, HasHumans(s, bots))
+	s.Players[1].Dead = true
+	assert.False(t, HasHumans(s, bots))
+
+	assert.Empty(t, PlayBots(s, bots), "bots alone don't play")
+	assert.Equal(t, 0, s.Turn())
+}
@@ -0,0 +1,292 @@
+package svg
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+const DefaultCellSize = 40
+
+const (
+	earthColor        = "#ffffff"
+	wallColor         = "#d0d0d0"
+	riverColor        = "#a6cbf0"
+	mouthColor        = "#4a8fd8"
+	holeColor         = "#7b3fa0"
+	doorColor         = "#8b5a2b"
+	exitColor         = "#3cb44b"
+	lineColor         = "#000000"
+	gridColor         = "#c8c8c8"
+	labelColor        = "#505050"
+	treasureColor     = "#e6b800"
+	fakeTreasureColor = "#9a9a9a"
+)
+
+type Options struct {
+	// CellSize is the side of a cell, DefaultCellSize is used if it's zero
+	CellSize int
+	// View is the map of a player: only known cells are drawn, items and wormhole labels are hidden and coordinates
+	// start at the corner of the map. The whole map is drawn if it's nil
+	View *lab.PlayerMap
+}
+
+func (o Options) cellSize() int {
+	if o.CellSize <= 0 {
+		return DefaultCellSize
+	}
+
+	return o.CellSize
+}
+
+type canvas struct {
+	sb    strings.Builder
+	cells *lab.CellMap
+	view  *lab.PlayerMap
+	size  int
+	// corner is the top left cell of the picture
+	corner lab.Position
+	// margin leaves space for coordinates
+	margin int
+}
+
+// Write draws the map. Walls are grey with thick lines where they meet passable cells, rivers show where they flow,
+// wormholes have their system and index like in the markdown map and coordinates match the markdown table header.
+func Write(w io.Writer, cells *lab.CellMap, opts Options) error {
+	c := &canvas{
+		cells:  cells,
+		view:   opts.View,
+		size:   opts.cellSize(),
+		margin: opts.cellSize() * 3 / 4,
+	}
+
+	cols, rows := cells.Cols(), cells.Rows()
+	if c.view != nil {
+		c.corner = c.view.LeftCorner
+		cols, rows = c.view.Rect()
+	}
+
+	width, height := c.margin+cols*c.size+1, c.margin+rows*c.size+1
+	fmt.Fprintf(&c.sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%v" height="%v" viewBox="0 0 %v %v" font-family="sans-serif">`+"\n", width, height, width, height)
+	fmt.Fprintf(&c.sb, `<rect width="%v" height="%v" fill="%v"/>`+"\n", width, height, earthColor)
+
+	var visible []lab.Position
+	for y := c.corner.Y; y < c.corner.Y+rows; y++ {
+		for x := c.corner.X; x < c.corner.X+cols; x++ {
+			if pos := lab.NewPosition(x, y); c.visible(pos) {
+				visible = append(visible, pos)
+			}
+		}
+	}
+
+	for _, pos := range visible {
+		c.cell(pos)
+	}
+	for _, pos := range visible {
+		c.walls(pos)
+	}
+	c.labels(cols, rows)
+
+	c.sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, c.sb.String())
+	return err
+}
+
+func (c *canvas) visible(pos lab.Position) bool {
+	if pos.X < 0 || pos.Y < 0 || pos.X >= c.cells.Cols() || pos.Y >= c.cells.Rows() {
+		return false
+	}
+	if c.view == nil {
+		return true
+	}
+
+	_, ok := c.view.KnonwnCells[pos]
+	return ok
+}
+
+// origin is the top left corner of the cell in the picture
+func (c *canvas) origin(pos lab.Position) (int, int) {
+	return c.margin + (pos.X-c.corner.X)*c.size, c.margin + (pos.Y-c.corner.Y)*c.size
+}
+
+func (c *canvas) rect(pos lab.Position, fill string) {
+	x, y := c.origin(pos)
+	fmt.Fprintf(&c.sb, `<rect x="%v" y="%v" width="%v" height="%v" fill="%v" stroke="%v" stroke-width="1"/>`+"\n", x, y, c.size, c.size, fill, gridColor)
+}
+
+func (c *canvas) text(pos lab.Position, dy int, fontSize int, fill string, text string) {
+	x, y := c.origin(pos)
+	fmt.Fprintf(&c.sb, `<text x="%v" y="%v" font-size="%v" fill="%v" text-anchor="middle" dominant-baseline="central">%v</text>`+"\n",
+		x+c.size/2, y+c.size/2+dy, fontSize, fill, html.EscapeString(text))
+}
+
+func (c *canvas) cell(pos lab.Position) {
+	cell := c.cells.Get(pos)
+
+	switch cell.Class {
+	case lab.CellWall:
+		c.rect(pos, wallColor)
+	case lab.CellRiver:
+		c.river(pos, cell)
+	case lab.CellWormHole:
+		c.rect(pos, earthColor)
+		c.wormhole(pos, cell)
+	case lab.CellDoor:
+		c.rect(pos, earthColor)
+		c.door(pos, cell)
+	case lab.CellExit:
+		c.rect(pos, earthColor)
+		x, y := c.origin(pos)
+		inset := c.size / 8
+		fmt.Fprintf(&c.sb, `<rect x="%v" y="%v" width="%v" height="%v" fill="none" stroke="%v" stroke-width="%v"/>`+"\n",
+			x+inset, y+inset, c.size-2*inset, c.size-2*inset, exitColor, max(2, c.size/16))
+		c.text(pos, 0, c.size/3, exitColor, "exit")
+	default:
+		c.rect(pos, earthColor)
+	}
+
+	if c.view == nil {
+		c.items(pos, cell)
+	}
+}
+
+// degrees turns an arrow pointing east to the direction
+func degrees(dir lab.MoveDirection) int {
+	switch dir {
+	case lab.South:
+		return 90
+	case lab.West:
+		return 180
+	case lab.North:
+		return 270
+	}
+
+	return 0
+}
+
+func (c *canvas) river(pos lab.Position, cell lab.Cell) {
+	river, ok := cell.Custom.(*lab.RiverCell)
+	if !ok || river.Dir == lab.MoveNil {
+		c.rect(pos, mouthColor)
+		c.text(pos, 0, c.size/3, earthColor, "RM")
+		return
+	}
+
+	c.rect(pos, riverColor)
+
+	x, y := c.origin(pos)
+	cx, cy := x+c.size/2, y+c.size/2
+	l, head := c.size*3/10, c.size/6
+	fmt.Fprintf(&c.sb, `<path d="M %v %v L %v %v M %v %v L %v %v L %v %v" fill="none" stroke="%v" stroke-width="%v" stroke-linecap="round" stroke-linejoin="round" transform="rotate(%v %v %v)"/>`+"\n",
+		cx-l, cy, cx+l, cy, cx+l-head, cy-head, cx+l, cy, cx+l-head, cy+head,
+		mouthColor, max(2, c.size/14), degrees(river.Dir), cx, cy)
+}
+
+func (c *canvas) wormhole(pos lab.Position, cell lab.Cell) {
+	x, y := c.origin(pos)
+	fmt.Fprintf(&c.sb, `<circle cx="%v" cy="%v" r="%v" fill="none" stroke="%v" stroke-width="%v"/>`+"\n",
+		x+c.size/2, y+c.size/2, c.size*2/5, holeColor, max(2, c.size/16))
+
+	// players shouldn't learn from their map where a wormhole leads
+	if hole, ok := cell.Custom.(*lab.WormholeCell); ok && c.view == nil {
+		c.text(pos, 0, c.size/3, holeColor, fmt.Sprintf("%v:%v", hole.Name, hole.Idx))
+	}
+}
+
+func (c *canvas) door(pos lab.Position, cell lab.Cell) {
+	x, y := c.origin(pos)
+	fmt.Fprintf(&c.sb, `<rect x="%v" y="%v" width="%v" height="%v" fill="%v"/>`+"\n",
+		x+c.size/8, y+c.size/8, c.size*3/4, c.size/5, doorColor)
+
+	if door, ok := cell.Custom.(*lab.DoorCell); ok {
+		c.text(pos, c.size/6, c.size/4, doorColor, door.Key)
+	}
+}
+
+func (c *canvas) items(pos lab.Position, cell lab.Cell) {
+	x, y := c.origin(pos)
+	r := c.size / 8
+	for i, item := range cell.Items {
+		cx, cy := x+r+2+i*(2*r+2), y+c.size-r-2
+
+		switch item.ID {
+		case lab.Treasure:
+			fmt.Fprintf(&c.sb, `<path d="M %v %v L %v %v L %v %v L %v %v Z" fill="%v" stroke="%v"/>`+"\n",
+				cx, cy-r, cx+r, cy, cx, cy+r, cx-r, cy, treasureColor, lineColor)
+		case lab.FakeTreasure:
+			fmt.Fprintf(&c.sb, `<path d="M %v %v L %v %v L %v %v L %v %v Z" fill="%v" stroke="%v"/>`+"\n",
+				cx, cy-r, cx+r, cy, cx, cy+r, cx-r, cy, fakeTreasureColor, lineColor)
+		default:
+			fmt.Fprintf(&c.sb, `<circle cx="%v" cy="%v" r="%v" fill="none" stroke="%v"/><text x="%v" y="%v" font-size="%v" fill="%v" dominant-baseline="central">%v</text>`+"\n",
+				cx, cy, r*2/3, lineColor, cx+r, cy, c.size/5, lineColor, html.EscapeString(item.Tag))
+		}
+	}
+}
+
+func (c *canvas) isWall(pos lab.Position) bool {
+	return c.cells.Get(pos).Class == lab.CellWall
+}
+
+// walls draws lines on the sides where a wall meets a cell which isn't a wall
+func (c *canvas) walls(pos lab.Position) {
+	if !c.isWall(pos) {
+		return
+	}
+
+	x, y := c.origin(pos)
+	sides := map[lab.MoveDirection][4]int{
+		lab.North: {x, y, x + c.size, y},
+		lab.East:  {x + c.size, y, x + c.size, y + c.size},
+		lab.South: {x, y + c.size, x + c.size, y + c.size},
+		lab.West:  {x, y, x, y + c.size},
+	}
+
+	for _, dir := range []lab.MoveDirection{lab.North, lab.East, lab.South, lab.West} {
+		next := pos.Next(dir)
+		if !c.visible(next) || c.isWall(next) {
+			continue
+		}
+
+		s := sides[dir]
+		fmt.Fprintf(&c.sb, `<line x1="%v" y1="%v" x2="%v" y2="%v" stroke="%v" stroke-width="%v" stroke-linecap="square"/>`+"\n",
+			s[0], s[1], s[2], s[3], lineColor, max(3, c.size/10))
+	}
+}
+
+// labels writes coordinates of the maze the same way the markdown table header does, outer walls have none.
+// A player's map is numbered from its own corner, so the labels don't tell where the player is
+func (c *canvas) labels(cols, rows int) {
+	fontSize := c.size / 3
+	for x := c.corner.X; x < c.corner.X+cols; x++ {
+		label, ok := c.label(x, c.corner.X, c.cells.Cols())
+		if !ok {
+			continue
+		}
+		px, _ := c.origin(lab.NewPosition(x, c.corner.Y))
+		fmt.Fprintf(&c.sb, `<text x="%v" y="%v" font-size="%v" fill="%v" text-anchor="middle" dominant-baseline="central">%v</text>`+"\n",
+			px+c.size/2, c.margin/2, fontSize, labelColor, label)
+	}
+
+	for y := c.corner.Y; y < c.corner.Y+rows; y++ {
+		label, ok := c.label(y, c.corner.Y, c.cells.Rows())
+		if !ok {
+			continue
+		}
+		_, py := c.origin(lab.NewPosition(c.corner.X, y))
+		fmt.Fprintf(&c.sb, `<text x="%v" y="%v" font-size="%v" fill="%v" text-anchor="middle" dominant-baseline="central">%v</text>`+"\n",
+			c.margin/2, py+c.size/2, fontSize, labelColor, label)
+	}
+}
+
+// label is the coordinate written for the row or column v, the corner is where the picture starts
+func (c *canvas) label(v, corner, size int) (int, bool) {
+	if c.view != nil {
+		return v - corner + 1, true
+	}
+
+	return v, v >= 1 && v <= size-2
+}
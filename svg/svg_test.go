@@ -0,0 +1,97 @@
+package svg
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+	md "github.com/kepkin/labyrinth/markdown"
+)
+
+const testMap = `| X | 1     | 2  | 3 | 4     |
+|---|-------|----|---|-------|
+| 1 | W:A:0 | R  | R | RM    |
+| 2 |       | w  |   | D:red |
+| 3 |       |    |   | W:A:1 |
+
+exit: 5:3
+treasure: 3:3
+key:red: 1:3
+alex: 1:2
+`
+
+func buildMap(t *testing.T) *lab.World {
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, _, err := wb.Build(testMap)
+	assert.NoError(t, err)
+
+	return w
+}
+
+// texts returns every text of the picture and checks it's well formed
+func texts(t *testing.T, src string) []string {
+	var res []string
+	dec := xml.NewDecoder(strings.NewReader(src))
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			break
+		}
+
+		switch v := tok.(type) {
+		case xml.StartElement:
+			inText = v.Name.Local == "text"
+		case xml.CharData:
+			if inText {
+				res = append(res, string(v))
+			}
+		case xml.EndElement:
+			inText = false
+		}
+	}
+
+	return res
+}
+
+func TestWrite(t *testing.T) {
+	w := buildMap(t)
+
+	sb := strings.Builder{}
+	assert.NoError(t, Write(&sb, &w.Cells, Options{CellSize: 20}))
+	src := sb.String()
+
+	assert.Equal(t, []string{"A:0", "RM", "red", "red", "A:1", "exit", "1", "2", "3", "4", "1", "2", "3"}, texts(t, src))
+	assert.Contains(t, src, `width="136" height="116"`)
+	assert.Contains(t, src, `rotate(0 65 45)`, "river at 2:1 flows east")
+	assert.Equal(t, 2, strings.Count(src, "rotate(0 "))
+	assert.Contains(t, src, `fill="`+treasureColor+`"`)
+
+	// the wall at 2:2 is surrounded by passable cells
+	assert.Contains(t, src, `<line x1="55" y1="55" x2="75" y2="55"`)
+	assert.Contains(t, src, `<line x1="75" y1="55" x2="75" y2="75"`)
+}
+
+func TestWrite_PlayerMap(t *testing.T) {
+	w := buildMap(t)
+
+	view := lab.NewPlayerMap(lab.NewPosition(1, 2))
+	view.Learn(lab.NewPosition(1, 1))
+	view.Learn(lab.NewPosition(0, 2))
+
+	sb := strings.Builder{}
+	assert.NoError(t, Write(&sb, &w.Cells, Options{CellSize: 20, View: &view}))
+	src := sb.String()
+
+	assert.Equal(t, []string{"1", "2", "1", "2"}, texts(t, src), "no wormhole label, coordinates start at the corner of the map")
+	assert.Contains(t, src, `width="56" height="56"`)
+	assert.NotContains(t, src, treasureColor)
+	assert.Equal(t, 1, strings.Count(src, "<line"), "only the wall next to 1:2 is known")
+}
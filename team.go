@@ -0,0 +1,79 @@
+package labyrinth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetPlayerTeam assigns a team to the player. Empty team means the player plays alone
+func (s *Session) SetPlayerTeam(name string, team string) error {
+	p := s.FindPlayer(name)
+	if p == nil {
+		return fmt.Errorf("no such player %v", name)
+	}
+
+	p.Team = team
+	return nil
+}
+
+func (s *Session) FindPlayer(name string) *Player {
+	for _, p := range s.Players {
+		if p.Name == name {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// Teammates returns other players of the same team
+func (s *Session) Teammates(p *Player) []*Player {
+	var res []*Player
+	if p.Team == "" {
+		return res
+	}
+
+	for _, v := range s.Players {
+		if v != p && v.Team == p.Team {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+// PlayerView returns the map the player sees: own map merged with maps of the teammates
+func (s *Session) PlayerView(p *Player) PlayerMap {
+	res := p.Map.Clone()
+	for _, v := range s.Teammates(p) {
+		res.Merge(v.Map)
+	}
+
+	return res
+}
+
+// give hands an item to a teammate standing on the same cell. Text format is `give <item> to <player>`
+func (s *Session) give(p *Player, text string) []Event {
+	itemName, receiverName, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(text, "give")), " to ")
+	if !ok {
+		return []Event{NewEventf2(ErrorEventType, p.Name, "use `give <item> to <player>`")}
+	}
+
+	receiver := s.FindPlayer(strings.TrimSpace(receiverName))
+	if receiver == nil || receiver == p || receiver.Pos != p.Pos || receiver.Team == "" || receiver.Team != p.Team {
+		return []Event{NewEventf2(ErrorEventType, p.Name, "no teammate to give to")}
+	}
+	if receiver.Inventory.IsFull() {
+		return []Event{NewEventf2(ErrorEventType, p.Name, "teammate's inventory is full")}
+	}
+
+	item := p.Inventory.Take(strings.TrimSpace(itemName))
+	if item == nil {
+		return []Event{NewEventf2(ErrorEventType, p.Name, "nothing to give")}
+	}
+	receiver.Inventory.Put(item)
+
+	e := NewEventf2(GiveObjectEventType, p.Name, receiver.Name)
+	s.World.Emmit(e)
+	return []Event{e}
+}
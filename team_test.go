@@ -0,0 +1,58 @@
+package labyrinth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_Give(t *testing.T) {
+	s := newTestSession(nil)
+	s.AddPlayer("bob", NewPosition(1, 1))
+	s.AddPlayer("eve", NewPosition(1, 1))
+	assert.NoError(t, s.SetPlayerTeam("alex", "red"))
+	assert.NoError(t, s.SetPlayerTeam("bob", "red"))
+
+	s.Do("pick up tresure")
+	assert.Contains(t, s.GetCurrentPlayerPossibleActions(), "give tresure to bob")
+	assert.NotContains(t, s.GetCurrentPlayerPossibleActions(), "give tresure to eve")
+
+	evs := s.Do("give tresure to eve")
+	if assert.Len(t, evs, 1) {
+		assert.Equal(t, EventType(ErrorEventType), evs[0].Type)
+	}
+
+	evs = s.Do("give tresure to bob")
+	if assert.Len(t, evs, 1) {
+		assert.Equal(t, EventType(GiveObjectEventType), evs[0].Type)
+	}
+	assert.True(t, s.Players[0].Inventory.IsEmpty())
+	assert.NotNil(t, s.Players[2].Inventory.Find(Treasure))
+}
+
+func TestSession_PlayerView(t *testing.T) {
+	s := newTestSession(nil)
+	for _, p := range s.Players {
+		p.NewMap()
+	}
+	assert.NoError(t, s.SetPlayerTeam("alex", "red"))
+
+	view := s.PlayerView(s.Players[0])
+	assert.Len(t, view.KnonwnCells, 1)
+
+	assert.NoError(t, s.SetPlayerTeam("tanya", "red"))
+	view = s.PlayerView(s.Players[0])
+	assert.Len(t, view.KnonwnCells, 2)
+	assert.Len(t, s.Players[0].Map.KnonwnCells, 1)
+}
+
+func TestScoreboard_Teams(t *testing.T) {
+	s := newTestSession(&LastSurvivor{})
+	s.AddPlayer("bob", NewPosition(2, 2))
+	assert.NoError(t, s.SetPlayerTeam("alex", "red"))
+	assert.NoError(t, s.SetPlayerTeam("bob", "red"))
+
+	s.Players[1].Dead = true
+	assert.True(t, s.Rule.IsOver(s, nil))
+	assert.Equal(t, "1. alex [red] (0), 1. bob [red] (0), 2. tanya (0)", s.Scoreboard().String())
+}
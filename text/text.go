@@ -0,0 +1,222 @@
+package text
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	lab "github.com/kepkin/labyrinth"
+)
+
+// Style is the alphabet maps are written with
+type Style int
+
+const (
+	// ASCII writes maps with plain characters, they look the same everywhere
+	ASCII Style = iota
+	// Unicode draws walls with box drawing characters and rivers with arrows
+	Unicode
+)
+
+type glyphs struct {
+	earth, mouth, wormhole, door, exit, unknown rune
+	// rivers are indexed by direction
+	rivers map[lab.MoveDirection]rune
+	// walls are indexed by the neighbours which are walls too, see wallGlyph
+	walls [16]rune
+}
+
+var asciiGlyphs = glyphs{
+	earth:    '.',
+	mouth:    'O',
+	wormhole: 'o',
+	door:     'D',
+	exit:     'E',
+	unknown:  ' ',
+	rivers:   map[lab.MoveDirection]rune{lab.North: '^', lab.East: '>', lab.South: 'v', lab.West: '<'},
+	walls:    [16]rune{'#', '#', '#', '#', '#', '#', '#', '#', '#', '#', '#', '#', '#', '#', '#', '#'},
+}
+
+var unicodeGlyphs = glyphs{
+	earth:    ' ',
+	mouth:    '◎',
+	wormhole: '○',
+	door:     '▥',
+	exit:     '⌂',
+	unknown:  '░',
+	rivers:   map[lab.MoveDirection]rune{lab.North: '↑', lab.East: '→', lab.South: '↓', lab.West: '←'},
+	//        none, N,   E,   NE,  S,   NS,  ES,  NES, W,   NW,  EW,  NEW, SW,  NSW, ESW, all
+	walls: [16]rune{'■', '│', '─', '└', '│', '│', '┌', '├', '─', '┘', '─', '┴', '┐', '┤', '┬', '┼'},
+}
+
+const (
+	wallNorth = 1 << iota
+	wallEast
+	wallSouth
+	wallWest
+)
+
+type Options struct {
+	Style Style
+	// View is the map of a player: only known cells are written, the others are unknown. The whole map is written
+	// if it's nil
+	View *lab.PlayerMap
+	// Markers are written over cells, for example players
+	Markers map[lab.Position]rune
+	// Rulers add coordinates like in the markdown table header above the map and on the left of it
+	Rulers bool
+}
+
+type writer struct {
+	sb     strings.Builder
+	cells  *lab.CellMap
+	view   *lab.PlayerMap
+	glyphs glyphs
+}
+
+// Write writes the map as text, a line per row
+func Write(w io.Writer, cells *lab.CellMap, opts Options) error {
+	_, err := io.WriteString(w, String(cells, opts))
+	return err
+}
+
+// String returns the map as text, a line per row
+func String(cells *lab.CellMap, opts Options) string {
+	wr := &writer{cells: cells, view: opts.View, glyphs: asciiGlyphs}
+	if opts.Style == Unicode {
+		wr.glyphs = unicodeGlyphs
+	}
+
+	corner := lab.Position{}
+	cols, rows := cells.Cols(), cells.Rows()
+	if opts.View != nil {
+		corner = opts.View.LeftCorner
+		cols, rows = opts.View.Rect()
+	}
+
+	margin := 0
+	if opts.Rulers {
+		margin = len(fmt.Sprint(corner.Y+rows-1)) + 1
+		wr.rulers(corner.X, cols, margin)
+	}
+
+	for y := corner.Y; y < corner.Y+rows; y++ {
+		if opts.Rulers {
+			fmt.Fprintf(&wr.sb, "%*d ", margin-1, y)
+		}
+		for x := corner.X; x < corner.X+cols; x++ {
+			pos := lab.NewPosition(x, y)
+			if mark, ok := opts.Markers[pos]; ok && wr.known(pos) {
+				wr.sb.WriteRune(mark)
+				continue
+			}
+			wr.sb.WriteRune(wr.glyph(pos))
+		}
+		wr.sb.WriteString("\n")
+	}
+
+	return wr.sb.String()
+}
+
+// rulers write the column numbers, tens are written above the tenth columns when the map is that wide
+func (wr *writer) rulers(left, cols int, margin int) {
+	if left+cols > 10 {
+		wr.sb.WriteString(strings.Repeat(" ", margin))
+		for x := left; x < left+cols; x++ {
+			if x%10 == 0 && x > 0 {
+				fmt.Fprint(&wr.sb, x/10%10)
+			} else {
+				wr.sb.WriteString(" ")
+			}
+		}
+		wr.sb.WriteString("\n")
+	}
+
+	wr.sb.WriteString(strings.Repeat(" ", margin))
+	for x := left; x < left+cols; x++ {
+		fmt.Fprint(&wr.sb, x%10)
+	}
+	wr.sb.WriteString("\n")
+}
+
+func (wr *writer) known(pos lab.Position) bool {
+	if pos.X < 0 || pos.Y < 0 || pos.X >= wr.cells.Cols() || pos.Y >= wr.cells.Rows() {
+		return false
+	}
+	if wr.view == nil {
+		return true
+	}
+
+	_, ok := wr.view.KnonwnCells[pos]
+	return ok
+}
+
+func (wr *writer) isWall(pos lab.Position) bool {
+	return wr.known(pos) && wr.cells.Get(pos).Class == lab.CellWall
+}
+
+func (wr *writer) glyph(pos lab.Position) rune {
+	if !wr.known(pos) {
+		return wr.glyphs.unknown
+	}
+
+	cell := wr.cells.Get(pos)
+	switch cell.Class {
+	case lab.CellWall:
+		return wr.wallGlyph(pos)
+	case lab.CellRiver:
+		if river, ok := cell.Custom.(*lab.RiverCell); ok && river.Dir != lab.MoveNil {
+			return wr.glyphs.rivers[river.Dir]
+		}
+		return wr.glyphs.mouth
+	case lab.CellWormHole:
+		return wr.glyphs.wormhole
+	case lab.CellDoor:
+		return wr.glyphs.door
+	case lab.CellExit:
+		return wr.glyphs.exit
+	}
+
+	return wr.glyphs.earth
+}
+
+// wallGlyph joins the wall with the known walls around it, so unknown cells aren't given away by the lines
+func (wr *writer) wallGlyph(pos lab.Position) rune {
+	idx := 0
+	if wr.isWall(pos.Next(lab.North)) {
+		idx |= wallNorth
+	}
+	if wr.isWall(pos.Next(lab.East)) {
+		idx |= wallEast
+	}
+	if wr.isWall(pos.Next(lab.South)) {
+		idx |= wallSouth
+	}
+	if wr.isWall(pos.Next(lab.West)) {
+		idx |= wallWest
+	}
+
+	return wr.glyphs.walls[idx]
+}
+
+// PlayerMarker is the mark of the player with the index: a digit, or a letter after the tenth player
+func PlayerMarker(idx int) rune {
+	const marks = "0123456789abcdefghijklmnopqrstuvwxyz"
+	if idx < 0 || idx >= len(marks) {
+		return '?'
+	}
+
+	return rune(marks[idx])
+}
+
+// PlayerMarkers marks players with PlayerMarker, dead players aren't marked
+func PlayerMarkers(players []*lab.Player) map[lab.Position]rune {
+	res := map[lab.Position]rune{}
+	for i, pl := range players {
+		if !pl.Dead {
+			res[pl.Pos] = PlayerMarker(i)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,133 @@
+package text
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	lab "github.com/kepkin/labyrinth"
+	md "github.com/kepkin/labyrinth/markdown"
+)
+
+const testMap = `| X | 1     | 2  | 3 | 4     |
+|---|-------|----|---|-------|
+| 1 | W:A:0 | R  | R | RM    |
+| 2 |       | w  |   | D:red |
+| 3 |       |    |   | W:A:1 |
+
+exit: 5:3
+treasure: 3:3
+key:red: 1:3
+alex: 1:2
+bob: 3:2
+`
+
+func buildMap(t *testing.T) (*lab.World, []*lab.Player) {
+	wb := md.WorldBuilder{Cf: lab.CellWorldBuilder{CellFac: lab.NewDefaultCellFactory()}}
+	w, pls, err := wb.Build(testMap)
+	assert.NoError(t, err)
+
+	return w, pls
+}
+
+func lines(s ...string) string {
+	return strings.Join(s, "\n") + "\n"
+}
+
+func TestString(t *testing.T) {
+	w, pls := buildMap(t)
+
+	view := lab.NewPlayerMap(lab.NewPosition(1, 2))
+	for _, pos := range []lab.Position{{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 0, Y: 3}, {X: 1, Y: 3}, {X: 2, Y: 2}} {
+		view.Learn(pos)
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "ascii",
+			opts: Options{},
+			want: lines(
+				"######",
+				"#o>>O#",
+				"#.#.D#",
+				"#...oE",
+				"######",
+			),
+		},
+		{
+			name: "unicode",
+			opts: Options{Style: Unicode},
+			want: lines(
+				"┌────┐",
+				"│○→→◎│",
+				"│ ■ ▥│",
+				"│   ○⌂",
+				"└─────",
+			),
+		},
+		{
+			name: "players and rulers",
+			opts: Options{Style: Unicode, Markers: PlayerMarkers(pls), Rulers: true},
+			want: lines(
+				"  012345",
+				"0 ┌────┐",
+				"1 │○→→◎│",
+				"2 │0■1▥│",
+				"3 │   ○⌂",
+				"4 └─────",
+			),
+		},
+		{
+			name: "known cells only",
+			opts: Options{Style: Unicode, View: &view, Markers: map[lab.Position]rune{{X: 1, Y: 2}: '@', {X: 3, Y: 2}: '1'}},
+			want: lines(
+				"│○→",
+				"│@■",
+				"│ ░",
+			),
+		},
+		{
+			name: "ascii known cells only",
+			opts: Options{View: &view},
+			want: lines(
+				"#o>",
+				"#.#",
+				"#. ",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, String(&w.Cells, tt.opts))
+		})
+	}
+}
+
+func TestString_Rulers(t *testing.T) {
+	w := lab.NewWorldFromString(`
+wwwwwwwwwwww
+w          w
+wwwwwwwwwwww
+`)
+
+	assert.Equal(t, lines(
+		"            1 ",
+		"  012345678901",
+		"0 ############",
+		"1 #..........#",
+		"2 ############",
+	), String(&w.Cells, Options{Rulers: true}))
+}
+
+func TestPlayerMarkers(t *testing.T) {
+	_, pls := buildMap(t)
+	pls[1].Dead = true
+
+	assert.Equal(t, map[lab.Position]rune{{X: 1, Y: 2}: '0'}, PlayerMarkers(pls))
+}
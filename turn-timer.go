@@ -0,0 +1,70 @@
+package labyrinth
+
+import (
+	"sync"
+	"time"
+)
+
+// TurnTimer limits the time of a turn. It warns at half time and reports the timeout when time runs out.
+type TurnTimer struct {
+	Clock   Clock
+	Timeout time.Duration
+
+	mu         sync.Mutex
+	timers     []Timer
+	generation int
+}
+
+func NewTurnTimer(clock Clock, timeout time.Duration) *TurnTimer {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+
+	return &TurnTimer{Clock: clock, Timeout: timeout}
+}
+
+// Reset stops the timers of the previous turn and arms them for a new one. A callback which has already passed
+// the generation check may still run after Reset, so callbacks should check they are still about the same turn
+func (t *TurnTimer) Reset(onWarning func(), onTimeout func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stop()
+	if t.Timeout <= 0 {
+		return
+	}
+
+	generation := t.generation
+	// callbacks of a previous turn may be already running, so they check the generation
+	guard := func(f func()) func() {
+		return func() {
+			t.mu.Lock()
+			current := t.generation == generation
+			t.mu.Unlock()
+
+			if current && f != nil {
+				f()
+			}
+		}
+	}
+
+	t.timers = append(t.timers,
+		t.Clock.AfterFunc(t.Timeout/2, guard(onWarning)),
+		t.Clock.AfterFunc(t.Timeout, guard(onTimeout)),
+	)
+}
+
+func (t *TurnTimer) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stop()
+}
+
+func (t *TurnTimer) stop() {
+	for _, v := range t.timers {
+		v.Stop()
+	}
+	t.timers = nil
+	t.generation++
+}
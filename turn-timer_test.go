@@ -0,0 +1,106 @@
+package labyrinth
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	res := !t.stopped
+	t.stopped = true
+	return res
+}
+
+type fakeClock struct {
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	t := &fakeTimer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves time forward and fires due timers synchronously
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].at.Before(c.timers[j].at) })
+	for _, t := range c.timers {
+		if !t.stopped && !t.at.After(c.now) {
+			t.stopped = true
+			t.f()
+		}
+	}
+}
+
+func TestTurnTimer(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	timer := NewTurnTimer(clock, time.Minute)
+
+	var calls []string
+	timer.Reset(func() { calls = append(calls, "warning") }, func() { calls = append(calls, "timeout") })
+
+	clock.Advance(20 * time.Second)
+	assert.Empty(t, calls)
+
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, []string{"warning"}, calls)
+
+	timer.Reset(nil, func() { calls = append(calls, "timeout 2") })
+	clock.Advance(40 * time.Second)
+	assert.Equal(t, []string{"warning"}, calls, "timers of the previous turn are stopped")
+
+	clock.Advance(20 * time.Second)
+	assert.Equal(t, []string{"warning", "timeout 2"}, calls)
+}
+
+func TestSession_AutoSkip(t *testing.T) {
+	s := newTestSession(nil)
+	s.MaxIdleSkips = 2
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	timer := NewTurnTimer(clock, time.Minute)
+
+	var evs []Event
+	var arm func()
+	arm = func() {
+		timer.Reset(nil, func() {
+			evs = append(evs, s.AutoSkip()...)
+			arm()
+		})
+	}
+	arm()
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, "tanya", s.GetCurrentPlayer().Name)
+
+	s.Do("north")
+	arm()
+	assert.Equal(t, "alex", s.GetCurrentPlayer().Name)
+
+	clock.Advance(time.Minute)
+
+	assert.Len(t, s.Players, 1)
+	assert.Equal(t, "tanya", s.Players[0].Name)
+	assert.Equal(t, EventType(PlayerRemovedEventType), evs[len(evs)-1].Type)
+	assert.Equal(t, "alex", evs[len(evs)-1].Subject)
+
+	last := s.History[len(s.History)-1]
+	assert.Equal(t, "skip", last.Action)
+	assert.Equal(t, evs[len(evs)-1], last.Events[len(last.Events)-1], "removal is recorded with the skip")
+}
@@ -46,6 +46,9 @@ func RunDebug(w *lab.World, sess *lab.Session) {
 				for _, pl := range sess.Players {
 					fmt.Fprintf(posView, "player pos %s", pl.Pos)
 				}
+				for _, m := range w.Monsters {
+					fmt.Fprintf(posView, "%v pos %s", m.Name, m.Pos)
+				}
 
 			})
 		}
@@ -2,6 +2,7 @@ package tview
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -21,13 +22,99 @@ type WorldTable struct {
 
 	w    *lab.World
 	sess *lab.Session
+	// viewer is the player whose knowledge is shown. The whole world is shown if it's nil
+	viewer *lab.Player
+	// trails are indexes of players who went through a cell
+	trails map[lab.Position][]int
+}
+
+// playerColors tell players' trails apart
+var playerColors = []tcell.Color{tcell.ColorRed, tcell.ColorBlue, tcell.ColorDarkMagenta, tcell.ColorDarkOrange, tcell.ColorTeal, tcell.ColorOlive}
+
+// PlayerColor is the colour of the player's trail
+func PlayerColor(idx int) tcell.Color {
+	return playerColors[idx%len(playerColors)]
+}
+
+// ShowPaths draws the paths of players in the whole world view
+func (m *WorldTable) ShowPaths(paths map[string][]lab.Position) {
+	m.trails = map[lab.Position][]int{}
+	for idx, p := range m.sess.Players {
+		for _, pos := range paths[p.Name] {
+			if !slices.Contains(m.trails[pos], idx) {
+				m.trails[pos] = append(m.trails[pos], idx)
+			}
+		}
+	}
+}
+
+// SetViewer shows only the maps of the player and their teammates, nil shows the whole world. Fragments of the
+// player's map aren't shown on the table
+func (m *WorldTable) SetViewer(p *lab.Player) {
+	m.viewer = p
+}
+
+func (m *WorldTable) Viewer() *lab.Player {
+	return m.viewer
+}
+
+// viewRect is the rect of the maps the viewer sees. Like image.PlayerMap the table shows only this rect, so
+// the viewer doesn't learn where on the world their map lies
+func (m *WorldTable) viewRect() (lab.Position, int, int) {
+	view := m.viewer.Map
+	for _, p := range m.sess.Teammates(m.viewer) {
+		view.LeftCorner.X = min(view.LeftCorner.X, p.Map.LeftCorner.X)
+		view.LeftCorner.Y = min(view.LeftCorner.Y, p.Map.LeftCorner.Y)
+		view.RightCorner.X = max(view.RightCorner.X, p.Map.RightCorner.X)
+		view.RightCorner.Y = max(view.RightCorner.Y, p.Map.RightCorner.Y)
+	}
+
+	cols, rows := view.Rect()
+	return view.LeftCorner, cols, rows
+}
+
+func (m *WorldTable) knows(pos lab.Position) bool {
+	for _, p := range append(m.sess.Teammates(m.viewer), m.viewer) {
+		if _, ok := p.Map.KnonwnCells[pos]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// visible tells if the player is shown to the viewer
+func (m *WorldTable) visible(p *lab.Player) bool {
+	if m.viewer == nil {
+		return true
+	}
+
+	if p != m.viewer && (p.Team == "" || p.Team != m.viewer.Team) {
+		return false
+	}
+
+	return !m.sess.HasUncertainty(p)
 }
 
 func (m *WorldTable) GetCell(row, column int) *tview.TableCell {
 	var ret *tview.TableCell
 	ret = tview.NewTableCell("")
 
-	worldCell := m.w.Cells.Get(lab.Position{X: column, Y: row})
+	pos := lab.Position{X: column, Y: row}
+	if m.viewer != nil {
+		left, _, _ := m.viewRect()
+		pos = lab.NewPosition(left.X+column, left.Y+row)
+	}
+
+	if m.viewer != nil && !m.knows(pos) {
+		ret = tview.NewTableCell("░")
+		ret.SetTextColor(tcell.ColorDimGray)
+		ret.SetBackgroundColor(tcell.ColorBlack)
+
+		return ret
+	}
+
+	worldCell := m.w.Cells.Get(pos)
 
 	switch worldCell.Class {
 	case "wall":
@@ -40,12 +127,65 @@ func (m *WorldTable) GetCell(row, column int) *tview.TableCell {
 		ret = tview.NewTableCell(rcell.Dir.Utf8Arrow())
 		ret.SetBackgroundColor(tcell.ColorCornflowerBlue)
 	case "wormhole":
-		ret = tview.NewTableCell(" ")
+		wcell := worldCell.Custom.(*lab.WormholeCell)
+
+		ret = tview.NewTableCell(wcell.Name)
+		if m.viewer != nil {
+			ret.SetText(" ")
+		}
 		ret.SetBackgroundColor(tcell.ColorDarkGreen)
+	case "exit":
+		ret = tview.NewTableCell("E")
+		ret.SetBackgroundColor(tcell.ColorYellow)
+		ret.SetTextColor(tcell.ColorBlack)
+	case "door":
+		ret = tview.NewTableCell("D")
+		ret.SetBackgroundColor(tcell.ColorSaddleBrown)
+	}
+
+	if m.viewer != nil {
+		return m.playerMarks(ret, pos)
+	}
+
+	if len(worldCell.Items) > 0 {
+		ret.SetText("*")
+	}
+
+	if trail := m.trails[pos]; len(trail) == 1 {
+		ret.SetText("·")
+		ret.SetTextColor(PlayerColor(trail[0]))
+	} else if len(trail) > 1 {
+		ret.SetText("+")
+	}
+
+	if m.w.MonsterAt(pos) != nil {
+		ret.SetText("M")
+		ret.SetTextColor(tcell.ColorRed)
 	}
 
 	for idx, p := range m.sess.Players {
-		if p.Pos.X == column && p.Pos.Y == row {
+		if p.Pos == pos {
+			ret.SetText(fmt.Sprintf("%v", idx))
+			if m.trails != nil {
+				ret.SetTextColor(PlayerColor(idx))
+			}
+		}
+	}
+
+	return ret
+}
+
+// playerMarks shows the viewer and teammates who know where they are. Items are seen only on their own cells,
+// monsters and other players are never shown
+func (m *WorldTable) playerMarks(ret *tview.TableCell, pos lab.Position) *tview.TableCell {
+	for idx, p := range m.sess.Players {
+		if p.Pos != pos || !m.visible(p) {
+			continue
+		}
+
+		if len(m.w.Cells.Get(pos).Items) > 0 {
+			ret.SetText(fmt.Sprintf("%v*", idx))
+		} else {
 			ret.SetText(fmt.Sprintf("%v", idx))
 		}
 	}
@@ -54,9 +194,19 @@ func (m *WorldTable) GetCell(row, column int) *tview.TableCell {
 }
 
 func (m *WorldTable) GetRowCount() int {
+	if m.viewer != nil {
+		_, _, rows := m.viewRect()
+		return rows
+	}
+
 	return m.w.Dimensions().Height
 }
 
 func (m *WorldTable) GetColumnCount() int {
+	if m.viewer != nil {
+		_, cols, _ := m.viewRect()
+		return cols
+	}
+
 	return m.w.Dimensions().Width
 }
@@ -14,6 +14,10 @@ type Cell = *CellType
 type Item struct {
 	ID   HandItem
 	Name string
+	// Tag distinguishes items of the same kind, e.g. a key colour
+	Tag string
+	// Value is the amount of points a treasure brings
+	Value int
 }
 type CellType struct {
 	Class      string
@@ -209,6 +213,18 @@ const (
 	RevealObjectEventType
 	TeleportEventType
 	GameStartEventType
+	LockedDoorEventType
+	UnlockDoorEventType
+	MonsterNearEventType
+	MonsterAttackEventType
+	PlayerDiedEventType
+	TreasureOutEventType
+	GameOverEventType
+	GiveObjectEventType
+	RoundEndEventType
+	SkipEventType
+	PlayerRemovedEventType
+	MapMergedEventType
 )
 
 type Event struct {
@@ -276,6 +292,42 @@ func (DefaultEventStringer) ToString(ev Event) string {
 	case GameStartEventType:
 		return fmt.Sprintf("Game started. Player %v is the first to move", ev.Subject)
 
+	case LockedDoorEventType:
+		return fmt.Sprintf("Player %v found a locked door", ev.Subject)
+
+	case UnlockDoorEventType:
+		return fmt.Sprintf("Player %v unlocked a door", ev.Subject)
+
+	case MonsterNearEventType:
+		return fmt.Sprintf("Player %v hears the %v nearby", ev.Subject, ev.Value)
+
+	case MonsterAttackEventType:
+		return fmt.Sprintf("Player %v was attacked by the %v", ev.Subject, ev.Value)
+
+	case PlayerDiedEventType:
+		return fmt.Sprintf("Player %v died", ev.Subject)
+
+	case TreasureOutEventType:
+		return fmt.Sprintf("Player %v carried out a treasure worth %v", ev.Subject, ev.Value)
+
+	case GameOverEventType:
+		return fmt.Sprintf("Game over: %v", ev.Value)
+
+	case GiveObjectEventType:
+		return fmt.Sprintf("Player %v handed an item to %v", ev.Subject, ev.Value)
+
+	case RoundEndEventType:
+		return fmt.Sprintf("Round %v is over", ev.Value)
+
+	case SkipEventType:
+		return fmt.Sprintf("Player %v skipped the turn", ev.Subject)
+
+	case PlayerRemovedEventType:
+		return fmt.Sprintf("Player %v was removed from the game", ev.Subject)
+
+	case MapMergedEventType:
+		return fmt.Sprintf("Player %v recognized a known place", ev.Subject)
+
 	}
 
 	return "Unsupported event"
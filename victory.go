@@ -0,0 +1,188 @@
+package labyrinth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type Score struct {
+	Player string
+	Team   string
+	Points int
+	Alive  bool
+	Place  int
+}
+
+type Scoreboard []Score
+
+func (sb Scoreboard) String() string {
+	res := make([]string, 0, len(sb))
+	for _, v := range sb {
+		if v.Team != "" {
+			res = append(res, fmt.Sprintf("%v. %v [%v] (%v)", v.Place, v.Player, v.Team, v.Points))
+		} else {
+			res = append(res, fmt.Sprintf("%v. %v (%v)", v.Place, v.Player, v.Points))
+		}
+	}
+
+	return strings.Join(res, ", ")
+}
+
+// VictoryRule decides when a session is over and how players are ranked
+type VictoryRule interface {
+	// IsOver is called after every move with the events it produced
+	IsOver(s *Session, evs []Event) bool
+	Rank(s *Session) Scoreboard
+}
+
+// side is a team or a single player without a team. Rules are applied to sides
+func side(p *Player) string {
+	if p.Team != "" {
+		return "team:" + p.Team
+	}
+
+	return "player:" + p.Name
+}
+
+// rankByPoints ranks sides: the side of `first` player goes first, then alive sides, then sides with more points.
+// Teammates share the same place.
+func rankByPoints(s *Session, first string) Scoreboard {
+	firstSide := ""
+	sidePoints := map[string]int{}
+	sideAlive := map[string]bool{}
+	sideOrder := map[string]int{}
+	for i, p := range s.Players {
+		if _, ok := sideOrder[side(p)]; !ok {
+			sideOrder[side(p)] = i
+		}
+		if p.Name == first {
+			firstSide = side(p)
+		}
+		sidePoints[side(p)] += p.Score
+		sideAlive[side(p)] = sideAlive[side(p)] || !p.Dead
+	}
+
+	res := make(Scoreboard, 0, len(s.Players))
+	sides := make([]string, 0, len(s.Players))
+	for _, p := range s.Players {
+		res = append(res, Score{Player: p.Name, Team: p.Team, Points: p.Score, Alive: !p.Dead})
+		sides = append(sides, side(p))
+	}
+
+	idx := make([]int, len(res))
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(i, j int) int {
+		a, b := sides[i], sides[j]
+		switch {
+		case a == b:
+			return 0
+		case a == firstSide:
+			return -1
+		case b == firstSide:
+			return 1
+		case sideAlive[a] != sideAlive[b]:
+			if sideAlive[a] {
+				return -1
+			}
+			return 1
+		case sidePoints[a] != sidePoints[b]:
+			return sidePoints[b] - sidePoints[a]
+		}
+
+		return sideOrder[a] - sideOrder[b]
+	})
+
+	sorted := make(Scoreboard, 0, len(res))
+	place := 0
+	lastSide := ""
+	for _, i := range idx {
+		if sides[i] != lastSide {
+			place++
+			lastSide = sides[i]
+		}
+		res[i].Place = place
+		sorted = append(sorted, res[i])
+	}
+
+	return sorted
+}
+
+// FirstOutWithTreasure is the classic rule: the first player to carry a genuine treasure out wins
+type FirstOutWithTreasure struct {
+	winner string
+}
+
+func (r *FirstOutWithTreasure) IsOver(s *Session, evs []Event) bool {
+	for _, e := range evs {
+		if e.Type == TreasureOutEventType {
+			r.winner = e.Subject
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *FirstOutWithTreasure) Rank(s *Session) Scoreboard {
+	return rankByPoints(s, r.winner)
+}
+
+// MostPoints ends the game after MaxTurns moves, the player with most treasure points wins
+type MostPoints struct {
+	MaxTurns int
+}
+
+func (r *MostPoints) IsOver(s *Session, evs []Event) bool {
+	return s.Turn() >= r.MaxTurns
+}
+
+func (r *MostPoints) Rank(s *Session) Scoreboard {
+	return rankByPoints(s, "")
+}
+
+// LastSurvivor ends the game when only one player or team is alive. A game of one side goes on until it dies
+type LastSurvivor struct {
+	// sides is the number of sides which have played, players who left the game still count
+	sides map[string]struct{}
+}
+
+func (r *LastSurvivor) IsOver(s *Session, evs []Event) bool {
+	if r.sides == nil {
+		r.sides = map[string]struct{}{}
+	}
+
+	alive := map[string]struct{}{}
+	for _, p := range s.Players {
+		r.sides[side(p)] = struct{}{}
+		if !p.Dead {
+			alive[side(p)] = struct{}{}
+		}
+	}
+
+	// the game ends only when someone is out
+	return len(alive) <= 1 && len(alive) < len(r.sides)
+}
+
+func (r *LastSurvivor) Rank(s *Session) Scoreboard {
+	return rankByPoints(s, "")
+}
+
+// NewVictoryRule makes a rule by its name: `first-out`, `most-points` or `last-survivor`
+func NewVictoryRule(name string, maxTurns int) (VictoryRule, error) {
+	switch name {
+	case "first-out", "":
+		return &FirstOutWithTreasure{}, nil
+	case "most-points":
+		if maxTurns <= 0 {
+			return nil, fmt.Errorf("most-points rule requires a positive number of turns")
+		}
+		return &MostPoints{MaxTurns: maxTurns}, nil
+	case "last-survivor":
+		return &LastSurvivor{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown victory rule `%v`", name)
+}
@@ -0,0 +1,99 @@
+package labyrinth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSession(rule VictoryRule) *Session {
+	w := NewWorldFromString(`
+wwww
+w  e
+w  w
+wwww
+`)
+	w.Cells.Get(NewPosition(1, 1)).PutItem(&Item{ID: Treasure, Name: "tresure", Value: 5})
+
+	s := &Session{World: w, Rule: rule}
+	s.AddPlayer("alex", NewPosition(1, 1))
+	s.AddPlayer("tanya", NewPosition(1, 2))
+
+	return s
+}
+
+func TestSession_FirstOutWithTreasure(t *testing.T) {
+	s := newTestSession(nil)
+
+	s.Do("pick up tresure")
+	s.Do("east")
+	s.Do("west")
+
+	evs := s.Do("east")
+	assert.True(t, s.IsOver())
+
+	types := []EventType{}
+	for _, e := range evs {
+		types = append(types, e.Type)
+	}
+	assert.Contains(t, types, EventType(TreasureOutEventType))
+	assert.Contains(t, types, EventType(WinEventType))
+	assert.Contains(t, types, EventType(GameOverEventType))
+
+	assert.Equal(t, Scoreboard{
+		{Player: "alex", Points: 5, Alive: true, Place: 1},
+		{Player: "tanya", Points: 0, Alive: true, Place: 2},
+	}, s.Scoreboard())
+
+	evs = s.Do("west")
+	if assert.Len(t, evs, 1) {
+		assert.Equal(t, EventType(ErrorEventType), evs[0].Type)
+	}
+}
+
+func TestSession_MostPoints(t *testing.T) {
+	s := newTestSession(&MostPoints{MaxTurns: 3})
+
+	s.Do("pick up tresure")
+	s.Do("east")
+	s.Do("north")
+	assert.False(t, s.IsOver())
+
+	s.Do("east")
+	assert.True(t, s.IsOver())
+	assert.Equal(t, "1. alex (5), 2. tanya (0)", s.Scoreboard().String())
+}
+
+func TestSession_LastSurvivor(t *testing.T) {
+	s := newTestSession(&LastSurvivor{})
+	s.Do("east")
+	assert.False(t, s.IsOver())
+
+	s.Players[0].Dead = true
+	evs := s.Do("north")
+	assert.True(t, s.IsOver())
+	assert.Equal(t, NewEventf2(WinEventType, "tanya", ""), evs[len(evs)-2])
+
+	solo := newTestSession(&LastSurvivor{})
+	solo.RemovePlayer("tanya")
+	solo.Do("east")
+	assert.False(t, solo.IsOver(), "a game of one side isn't over at the first move")
+
+	solo.Players[0].Dead = true
+	solo.Do("west")
+	assert.True(t, solo.IsOver())
+}
+
+func TestSession_DeadPlayerDoesNotWin(t *testing.T) {
+	s := newTestSession(&MostPoints{MaxTurns: 0})
+	s.Players[0].Score = 5
+	for _, p := range s.Players {
+		p.Dead = true
+	}
+
+	evs := s.checkGameOver(nil)
+	assert.True(t, s.IsOver())
+	if assert.Len(t, evs, 1) {
+		assert.Equal(t, EventType(GameOverEventType), evs[0].Type)
+	}
+}
@@ -74,26 +74,38 @@ func (cf *PrefixChainCellFactory) Finish(cm CellMap) error {
 var DefaultCellFactory *PrefixChainCellFactory
 
 func init() {
-	DefaultCellFactory = &PrefixChainCellFactory{}
+	DefaultCellFactory = NewDefaultCellFactory()
+}
+
+// NewDefaultCellFactory makes a factory with all cell types. Factories keep state while a map is built,
+// so a fresh one is needed to build maps again and again, like the map editor does
+func NewDefaultCellFactory() *PrefixChainCellFactory {
+	res := &PrefixChainCellFactory{}
 
-	_ = DefaultCellFactory.Register(
+	_ = res.Register(
 		[]string{"", " "},
 		SimpleStringCellFactory{func(pos Position) Cell { return &CellType{Class: CellEarth} }},
 	)
-	_ = DefaultCellFactory.Register(
+	_ = res.Register(
 		[]string{CellWall, "w"},
 		SimpleStringCellFactory{func(pos Position) Cell { return &CellType{Class: CellWall} }},
 	)
-	_ = DefaultCellFactory.Register(
+	_ = res.Register(
 		[]string{CellExit, "e"},
 		SimpleStringCellFactory{func(pos Position) Cell { return &CellType{Class: CellExit} }},
 	)
-	_ = DefaultCellFactory.Register(
+	_ = res.Register(
 		RiverStringFactoryKeys,
 		&RiverStringCellFactory{},
 	)
-	_ = DefaultCellFactory.Register(
+	_ = res.Register(
 		[]string{"W"},
 		&WormholeStringCellFactory{},
 	)
+	_ = res.Register(
+		[]string{"D"},
+		DoorStringCellFactory{},
+	)
+
+	return res
 }
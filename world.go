@@ -1,8 +1,9 @@
 package labyrinth
 
 type World struct {
-	Cells CellMap
-	ch    chan Event
+	Cells    CellMap
+	Monsters []*Monster
+	ch       chan Event
 }
 
 func (w *World) SetChannel(ch chan Event) {
@@ -21,6 +22,16 @@ func (w *World) Dimensions() Size {
 	return ret
 }
 
+func (w *World) MonsterAt(pos Position) *Monster {
+	for _, m := range w.Monsters {
+		if m.Pos == pos {
+			return m
+		}
+	}
+
+	return nil
+}
+
 func (w *World) Emmit(e Event) {
 	if w.ch != nil {
 		w.ch <- e